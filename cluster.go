@@ -37,9 +37,9 @@ type Engine interface {
 type Cluster struct {
 	lock sync.RWMutex
 
-	resolver   NodeNameResolver
-	engine     EngineInstance
-	validators map[string]KVValidator
+	resolver NodeNameResolver
+	engine   EngineInstance
+	registry *keyRegistry
 
 	*eventRegistry
 
@@ -57,6 +57,13 @@ type Cluster struct {
 
 	transactionID uint32
 
+	// snapshot fields.
+	snapshotStore    SnapshotStore
+	snapshotInterval time.Duration
+	snapshotLock     sync.Mutex
+	pendingSnapshot  *proto.Snapshot
+	stale            uint32
+
 	log Logger
 }
 
@@ -82,11 +89,13 @@ func NewClusterWithNameResolver(engine EngineInstance, resolver NodeNameResolver
 	nc := &Cluster{
 		resolver:      resolver,
 		engine:        engine,
-		validators:    make(map[string]KVValidator),
+		registry:      newKeyRegistry(),
 		nodes:         make(map[string]*Node),
 		emptyNodes:    make(map[*Node]struct{}),
 		conflictNodes: make(map[*Node]struct{}),
 		arbiter:       arbit.New(),
+
+		snapshotInterval: defaultSnapshotInterval,
 	}
 
 	for _, opt := range options {
@@ -95,6 +104,10 @@ func NewClusterWithNameResolver(engine EngineInstance, resolver NodeNameResolver
 			logger = o
 		case preserveUnnamedOption:
 			nc.PreserveUnnamed = bool(o)
+		case snapshotStoreOption:
+			nc.snapshotStore = o.SnapshotStore
+		case snapshotIntervalOption:
+			nc.snapshotInterval = time.Duration(o)
 		}
 	}
 	if logger == nil {
@@ -140,6 +153,15 @@ func NewClusterWithNameResolver(engine EngineInstance, resolver NodeNameResolver
 		return nil, nil, errs.AsError()
 	}
 
+	if nc.snapshotStore != nil {
+		// warm restart.
+		nc.loadSnapshot()
+		nc.tryRestoreSnapshot()
+		if nc.snapshotInterval > 0 {
+			nc.startSnapshotWorker()
+		}
+	}
+
 	return nc, nc.self, nil
 }
 
@@ -179,7 +201,7 @@ func (c *Cluster) getRealEntry(kv *KeyValue) *KeyValue {
 	if kv == nil {
 		return nil
 	}
-	validator, _ := c.validators[kv.Key]
+	validator := c.getKeyModel(kv.Key).getValidator()
 	if validator == nil {
 		// no validator. treat it unwrapped.
 		return kv
@@ -322,45 +344,6 @@ func (c *Cluster) mostPossibleNode(names []string) *Node {
 	return MostPossibleNode(names, c.getNode)
 }
 
-// RegisterKey registers key-value validator with specific key.
-func (c *Cluster) RegisterKey(key string, validator KVValidator, forceReplace bool, flags uint32) error {
-	var errs Errors
-
-	if err := c.Txn(func(t *Transaction) bool {
-		_, exists := c.validators[key]
-		if validator == nil {
-			if !exists {
-				return false
-			}
-		}
-
-		nodes := make([]*Node, 0)
-		t.RangeNode(func(node *Node) bool {
-			nodes = append(nodes, node)
-			return true
-		}, false, false)
-
-		for _, node := range nodes {
-			// replace validator.
-			if err := node.replaceValidator(t, key, validator, forceReplace); err != nil {
-				errs = append(errs, err)
-				return false
-			}
-		}
-
-		// assign the new
-		t.DeferOnCommit(func() {
-			c.validators[key] = validator
-		})
-
-		return true
-	}, MembershipModification()); err != nil {
-		errs = append(errs, err)
-	}
-
-	return errs.AsError()
-}
-
 func (c *Cluster) delayRemoveNode(n *Node) {
 	c.eventRegistry.queueLock.Lock()
 	defer c.eventRegistry.queueLock.Unlock()
@@ -771,5 +754,8 @@ func (c *Cluster) RemoveNode(node *Node) (removed bool, err error) {
 
 // Quit leaves cluster.
 func (c *Cluster) Quit() error {
+	if err := c.SaveSnapshot(); err != nil {
+		c.log.Warnf("failed to save snapshot before quiting. (err = \"%v\")", err)
+	}
 	return c.engine.Close()
 }
@@ -0,0 +1,228 @@
+package sladder
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var (
+	ErrCounterOriginMissing    = errors.New("missing origin of counter")
+	ErrCounterNotDecrementable = errors.New("grow-only counter cannot be decremented")
+)
+
+// counterState is per-origin counts of counter.
+type counterState struct {
+	P map[string]uint64 `json:"p,omitempty"` // increments.
+	N map[string]uint64 `json:"n,omitempty"` // decrements.
+}
+
+func (s *counterState) Encode() string {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+	return string(raw)
+}
+
+func (s *counterState) Decode(v string) error {
+	if v == "" {
+		v = "{}"
+	}
+	return json.Unmarshal([]byte(v), s)
+}
+
+func (s *counterState) Value() (v int64) {
+	for _, c := range s.P {
+		v += int64(c)
+	}
+	for _, c := range s.N {
+		v -= int64(c)
+	}
+	return
+}
+
+func mergeCounts(local *map[string]uint64, remote map[string]uint64) (changed bool) {
+	for origin, c := range remote {
+		if c <= (*local)[origin] {
+			continue
+		}
+		if *local == nil {
+			*local = make(map[string]uint64, len(remote))
+		}
+		(*local)[origin], changed = c, true
+	}
+	return
+}
+
+// Merge merges remote state by per-origin max.
+func (s *counterState) Merge(remote *counterState) bool {
+	p := mergeCounts(&s.P, remote.P)
+	n := mergeCounts(&s.N, remote.N)
+	return p || n
+}
+
+// CounterValue returns value of raw counter.
+func CounterValue(raw string) (int64, error) {
+	s := &counterState{}
+	if err := s.Decode(raw); err != nil {
+		return 0, err
+	}
+	return s.Value(), nil
+}
+
+func syncCounter(lr, rr *KeyValue, decrementable bool) (bool, error) {
+	if lr == nil {
+		return false, nil
+	}
+	if rr == nil {
+		return true, nil
+	}
+
+	remote, local := &counterState{}, &counterState{}
+	if err := remote.Decode(rr.Value); err != nil || (!decrementable && len(remote.N) > 0) { // reject invalid counter.
+		return false, ErrInvalidKeyValue
+	}
+	if err := local.Decode(lr.Value); err != nil {
+		// invalid local counter. replace with the remote.
+		lr.Value = rr.Value
+		return true, nil
+	}
+	if !local.Merge(remote) {
+		return false, nil
+	}
+	lr.Value = local.Encode()
+	return true, nil
+}
+
+func validateCounter(kv KeyValue, decrementable bool) bool {
+	s := &counterState{}
+	if err := s.Decode(kv.Value); err != nil {
+		return false
+	}
+	return decrementable || len(s.N) < 1
+}
+
+// GCounterValidator implements grow-only counter. Local increments are counted for Origin.
+type GCounterValidator struct {
+	Origin string
+}
+
+// Sync merges remote counter by per-origin max.
+func (v GCounterValidator) Sync(lr, rr *KeyValue) (bool, error) { return syncCounter(lr, rr, false) }
+
+// SyncEx merges remote counter. Concurrent updates need no special handling.
+func (v GCounterValidator) SyncEx(lr, rr *KeyValue, props KVMergingProperties) (bool, error) {
+	return v.Sync(lr, rr)
+}
+
+// Validate validates grow-only counter.
+func (v GCounterValidator) Validate(kv KeyValue) bool { return validateCounter(kv, false) }
+
+// Txn begins a counter transaction.
+func (v GCounterValidator) Txn(x KeyValue) (KVTransaction, error) {
+	return newCounterTxn(x, v.Origin, false)
+}
+
+// PNCounterValidator implements counter supporting both increments and decrements.
+// Local updates are counted for Origin.
+type PNCounterValidator struct {
+	Origin string
+}
+
+// Sync merges remote counter by per-origin max.
+func (v PNCounterValidator) Sync(lr, rr *KeyValue) (bool, error) { return syncCounter(lr, rr, true) }
+
+// SyncEx merges remote counter. Concurrent updates need no special handling.
+func (v PNCounterValidator) SyncEx(lr, rr *KeyValue, props KVMergingProperties) (bool, error) {
+	return v.Sync(lr, rr)
+}
+
+// Validate validates counter.
+func (v PNCounterValidator) Validate(kv KeyValue) bool { return validateCounter(kv, true) }
+
+// Txn begins a counter transaction.
+func (v PNCounterValidator) Txn(x KeyValue) (KVTransaction, error) {
+	return newCounterTxn(x, v.Origin, true)
+}
+
+// CounterTxn implements counter transaction.
+type CounterTxn struct {
+	state         counterState
+	origin        string
+	oldRaw        string
+	decrementable bool
+	changed       bool
+}
+
+func newCounterTxn(x KeyValue, origin string, decrementable bool) (*CounterTxn, error) {
+	txn := &CounterTxn{origin: origin, oldRaw: x.Value, decrementable: decrementable}
+	if err := txn.state.Decode(x.Value); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// After returns new counter.
+func (t *CounterTxn) After() string {
+	if !t.changed {
+		return t.oldRaw
+	}
+	return t.state.Encode()
+}
+
+// Updated checks whether counter is updated.
+func (t *CounterTxn) Updated() bool { return t.changed }
+
+// Before returns original counter.
+func (t *CounterTxn) Before() string { return t.oldRaw }
+
+// SetRawValue sets new counter.
+func (t *CounterTxn) SetRawValue(x string) error {
+	s := counterState{}
+	if err := s.Decode(x); err != nil {
+		return err
+	}
+	if !t.decrementable && len(s.N) > 0 {
+		return ErrCounterNotDecrementable
+	}
+	t.state, t.changed = s, x != t.oldRaw
+	return nil
+}
+
+// Value returns current value of counter.
+func (t *CounterTxn) Value() int64 { return t.state.Value() }
+
+// Increment increases counter by delta.
+func (t *CounterTxn) Increment(delta uint64) error {
+	if t.origin == "" {
+		return ErrCounterOriginMissing
+	}
+	if delta < 1 {
+		return nil
+	}
+	if t.state.P == nil {
+		t.state.P = make(map[string]uint64)
+	}
+	t.state.P[t.origin] += delta
+	t.changed = true
+	return nil
+}
+
+// Decrement decreases counter by delta.
+func (t *CounterTxn) Decrement(delta uint64) error {
+	if !t.decrementable {
+		return ErrCounterNotDecrementable
+	}
+	if t.origin == "" {
+		return ErrCounterOriginMissing
+	}
+	if delta < 1 {
+		return nil
+	}
+	if t.state.N == nil {
+		t.state.N = make(map[string]uint64)
+	}
+	t.state.N[t.origin] += delta
+	t.changed = true
+	return nil
+}
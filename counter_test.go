@@ -0,0 +1,130 @@
+package sladder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterValidator(t *testing.T) {
+	update := func(t *testing.T, v KVValidator, kv *KeyValue, fn func(*CounterTxn) error) {
+		rtx, err := v.Txn(*kv)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		txn := rtx.(*CounterTxn)
+		assert.NoError(t, fn(txn))
+		if txn.Updated() {
+			kv.Value = txn.After()
+		}
+	}
+	valueOf := func(t *testing.T, kv *KeyValue) int64 {
+		v, err := CounterValue(kv.Value)
+		assert.NoError(t, err)
+		return v
+	}
+
+	t.Run("g_counter", func(t *testing.T) {
+		v1, v2 := GCounterValidator{Origin: "n1"}, GCounterValidator{Origin: "n2"}
+		r1, r2 := &KeyValue{Key: "conns"}, &KeyValue{Key: "conns"}
+
+		update(t, v1, r1, func(txn *CounterTxn) error {
+			assert.Equal(t, ErrCounterNotDecrementable, txn.Decrement(1))
+			return txn.Increment(3)
+		})
+		update(t, v2, r2, func(txn *CounterTxn) error { return txn.Increment(2) })
+		update(t, v2, r2, func(txn *CounterTxn) error {
+			assert.Equal(t, int64(2), txn.Value())
+			return txn.Increment(0)
+		})
+
+		// concurrent updates converge.
+		changed, err := v1.Sync(r1, r2.Clone())
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		changed, err = v2.SyncEx(r2, r1.Clone(), nil)
+		assert.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, r1.Value, r2.Value)
+		assert.Equal(t, int64(5), valueOf(t, r1))
+
+		// merging is idempotent.
+		changed, err = v1.Sync(r1, r2.Clone())
+		assert.NoError(t, err)
+		assert.False(t, changed)
+
+		// stale remote never rolls back.
+		stale := &KeyValue{Key: "conns", Value: `{"p":{"n1":1}}`}
+		changed, err = v1.Sync(r1, stale)
+		assert.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, int64(5), valueOf(t, r1))
+
+		assert.False(t, v1.Validate(KeyValue{Key: "conns", Value: `{"n":{"n1":1}}`}))
+		assert.False(t, v1.Validate(KeyValue{Key: "conns", Value: "bad"}))
+		_, err = v1.Sync(r1, &KeyValue{Key: "conns", Value: `{"n":{"n1":1}}`})
+		assert.Equal(t, ErrInvalidKeyValue, err)
+
+		update(t, GCounterValidator{}, r1, func(txn *CounterTxn) error {
+			assert.Equal(t, ErrCounterOriginMissing, txn.Increment(1))
+			return nil
+		})
+	})
+
+	t.Run("pn_counter", func(t *testing.T) {
+		v1, v2 := PNCounterValidator{Origin: "n1"}, PNCounterValidator{Origin: "n2"}
+		r1, r2 := &KeyValue{Key: "conns"}, &KeyValue{Key: "conns"}
+
+		update(t, v1, r1, func(txn *CounterTxn) error { return txn.Increment(5) })
+		changed, err := v2.Sync(r2, r1.Clone())
+		assert.NoError(t, err)
+		assert.True(t, changed)
+
+		update(t, v1, r1, func(txn *CounterTxn) error { return txn.Decrement(2) })
+		update(t, v2, r2, func(txn *CounterTxn) error {
+			if err := txn.Increment(4); err != nil {
+				return err
+			}
+			return txn.Decrement(1)
+		})
+		assert.Equal(t, int64(3), valueOf(t, r1))
+		assert.Equal(t, int64(8), valueOf(t, r2))
+
+		_, err = v1.Sync(r1, r2.Clone())
+		assert.NoError(t, err)
+		_, err = v2.Sync(r2, r1.Clone())
+		assert.NoError(t, err)
+		assert.Equal(t, r1.Value, r2.Value)
+		assert.Equal(t, int64(6), valueOf(t, r1))
+		assert.True(t, v1.Validate(*r1))
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		c := newTestSnapshotCluster(t)
+		assert.NoError(t, c.RegisterKey("conns", PNCounterValidator{Origin: "self"}, false, 0))
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, c.Txn(func(tx *Transaction) bool {
+				rtx, err := tx.KV(c.Self(), "conns")
+				if !assert.NoError(t, err) {
+					return false
+				}
+				return assert.NoError(t, rtx.(*CounterTxn).Increment(2))
+			}))
+		}
+		assert.NoError(t, c.Txn(func(tx *Transaction) bool {
+			rtx, err := tx.KV(c.Self(), "conns")
+			if !assert.NoError(t, err) {
+				return false
+			}
+			txn := rtx.(*CounterTxn)
+			assert.NoError(t, txn.Decrement(1))
+			assert.Equal(t, int64(5), txn.Value())
+			return true
+		}))
+		for _, kv := range c.Self().KeyValueEntries(true) {
+			if kv.Key == "conns" {
+				assert.Equal(t, int64(5), valueOf(t, kv))
+			}
+		}
+	})
+}
@@ -0,0 +1,273 @@
+package sladder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/crossmesh/sladder/proto"
+)
+
+// KeyValueChange contains a change of entry value.
+type KeyValueChange struct {
+	Key      string
+	Old, New string // real (unwrapped) values.
+
+	OldRaw, NewRaw string // raw values.
+}
+
+// NodeDiff contains differences of a node between two snapshots. Values are real (unwrapped) ones.
+type NodeDiff struct {
+	OldNames, NewNames []string
+
+	Inserted []*KeyValue
+	Deleted  []*KeyValue
+	Changed  []*KeyValueChange
+}
+
+// NameChanged reports whether names of node are changed.
+func (d *NodeDiff) NameChanged() bool {
+	if len(d.OldNames) != len(d.NewNames) {
+		return true
+	}
+	for idx := range d.OldNames {
+		if d.OldNames[idx] != d.NewNames[idx] {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty reports whether there is no difference.
+func (d *NodeDiff) Empty() bool {
+	return len(d.Inserted)+len(d.Deleted)+len(d.Changed) < 1 && !d.NameChanged()
+}
+
+// SnapshotDiff contains differences between two snapshots.
+type SnapshotDiff struct {
+	Added   []*NodeDiff // nodes only in the new snapshot.
+	Removed []*NodeDiff // nodes only in the old snapshot.
+	Changed []*NodeDiff // nodes in both snapshots with differences.
+}
+
+// Empty reports whether two snapshots are identical.
+func (d *SnapshotDiff) Empty() bool { return len(d.Added)+len(d.Removed)+len(d.Changed) < 1 }
+
+func (d *SnapshotDiff) String() string {
+	var b strings.Builder
+
+	writeEntries := func(n *NodeDiff) {
+		for _, kv := range n.Inserted {
+			fmt.Fprintf(&b, "    + %v = %q\n", kv.Key, kv.Value)
+		}
+		for _, kv := range n.Deleted {
+			fmt.Fprintf(&b, "    - %v = %q\n", kv.Key, kv.Value)
+		}
+		for _, c := range n.Changed {
+			fmt.Fprintf(&b, "    ~ %v: %q -> %q\n", c.Key, c.Old, c.New)
+		}
+	}
+	for _, n := range d.Added {
+		fmt.Fprintf(&b, "+ node %v\n", n.NewNames)
+		writeEntries(n)
+	}
+	for _, n := range d.Removed {
+		fmt.Fprintf(&b, "- node %v\n", n.OldNames)
+		writeEntries(n)
+	}
+	for _, n := range d.Changed {
+		if n.NameChanged() {
+			fmt.Fprintf(&b, "~ node %v -> %v\n", n.OldNames, n.NewNames)
+		} else {
+			fmt.Fprintf(&b, "~ node %v\n", n.NewNames)
+		}
+		writeEntries(n)
+	}
+
+	return b.String()
+}
+
+type diffNode struct {
+	names   []string
+	entries map[string]string // real values.
+	raws    map[string]string
+	matched bool
+}
+
+func (n *diffNode) sameEntries(o *diffNode) bool {
+	if len(n.entries) != len(o.entries) {
+		return false
+	}
+	for key, value := range n.entries {
+		if origin, exists := o.entries[key]; !exists || origin != value {
+			return false
+		}
+	}
+	return true
+}
+
+func newDiffNodes(s *proto.Cluster, resolver NodeNameResolver, real func(*KeyValue) *KeyValue) ([]*diffNode, error) {
+	if s == nil {
+		return nil, nil
+	}
+	nameKeys := make(map[string]struct{})
+	for _, key := range resolver.Keys() {
+		nameKeys[key] = struct{}{}
+	}
+	nodes := make([]*diffNode, 0, len(s.Nodes))
+	for _, msg := range s.Nodes {
+		if msg == nil {
+			continue
+		}
+		node := &diffNode{
+			entries: make(map[string]string, len(msg.Kvs)),
+			raws:    make(map[string]string, len(msg.Kvs)),
+		}
+		var kvs []*KeyValue
+		for _, kv := range msg.Kvs {
+			entry := &KeyValue{Key: kv.Key, Value: kv.Value}
+			if real != nil {
+				entry = real(entry)
+			}
+			node.entries[kv.Key], node.raws[kv.Key] = entry.Value, kv.Value
+			if _, isNameKey := nameKeys[kv.Key]; isNameKey {
+				kvs = append(kvs, entry)
+			}
+		}
+		names, err := resolver.Resolve(kvs...)
+		if err != nil {
+			return nil, err
+		}
+		node.names = append(node.names, names...)
+		sort.Strings(node.names)
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func diffNodeEntries(old, new *diffNode) *NodeDiff {
+	d := &NodeDiff{}
+	if old != nil {
+		d.OldNames = old.names
+	} else {
+		old = &diffNode{}
+	}
+	if new != nil {
+		d.NewNames = new.names
+	} else {
+		new = &diffNode{}
+	}
+
+	for key, value := range new.entries {
+		if origin, exists := old.entries[key]; !exists {
+			d.Inserted = append(d.Inserted, &KeyValue{Key: key, Value: value})
+		} else if origin != value {
+			d.Changed = append(d.Changed, &KeyValueChange{
+				Key: key, Old: origin, New: value,
+				OldRaw: old.raws[key], NewRaw: new.raws[key],
+			})
+		}
+	}
+	for key, value := range old.entries {
+		if _, exists := new.entries[key]; !exists {
+			d.Deleted = append(d.Deleted, &KeyValue{Key: key, Value: value})
+		}
+	}
+	sort.Slice(d.Inserted, func(i, j int) bool { return d.Inserted[i].Key < d.Inserted[j].Key })
+	sort.Slice(d.Deleted, func(i, j int) bool { return d.Deleted[i].Key < d.Deleted[j].Key })
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].Key < d.Changed[j].Key })
+
+	return d
+}
+
+// DiffSnapshot compares two snapshots. Nodes are matched by names resolved by resolver.
+// A node matches the node sharing most names with it in the other snapshot.
+// Nodes without names match only nodes without names holding identical entries.
+// Values in snapshots are treated as real ones since validators are unknown.
+func DiffSnapshot(old, new *proto.Cluster, resolver NodeNameResolver) (*SnapshotDiff, error) {
+	return diffSnapshot(old, new, resolver, nil, nil)
+}
+
+func diffSnapshot(old, new *proto.Cluster, resolver NodeNameResolver, oldReal, newReal func(*KeyValue) *KeyValue) (*SnapshotDiff, error) {
+	if resolver == nil {
+		return nil, ErrMissingNameResolver
+	}
+
+	oldNodes, err := newDiffNodes(old, resolver, oldReal)
+	if err != nil {
+		return nil, err
+	}
+	newNodes, err := newDiffNodes(new, resolver, newReal)
+	if err != nil {
+		return nil, err
+	}
+
+	nameIndex := make(map[string]*diffNode)
+	for _, node := range oldNodes {
+		for _, name := range node.names {
+			nameIndex[name] = node
+		}
+	}
+
+	d := &SnapshotDiff{}
+	for _, node := range newNodes {
+		var matched *diffNode
+		hits, max := make(map[*diffNode]int), 0
+		for _, name := range node.names {
+			candidate, _ := nameIndex[name]
+			if candidate == nil || candidate.matched {
+				continue
+			}
+			hits[candidate]++
+			if hit := hits[candidate]; hit > max {
+				matched, max = candidate, hit
+			}
+		}
+		if matched == nil && len(node.names) < 1 {
+			for _, candidate := range oldNodes {
+				if !candidate.matched && len(candidate.names) < 1 && candidate.sameEntries(node) {
+					matched = candidate
+					break
+				}
+			}
+		}
+		if matched == nil {
+			d.Added = append(d.Added, diffNodeEntries(nil, node))
+			continue
+		}
+		matched.matched = true
+		if nd := diffNodeEntries(matched, node); !nd.Empty() {
+			d.Changed = append(d.Changed, nd)
+		}
+	}
+	for _, node := range oldNodes {
+		if !node.matched {
+			d.Removed = append(d.Removed, diffNodeEntries(node, nil))
+		}
+	}
+
+	nameOf := func(names []string) string { return strings.Join(names, ",") }
+	sort.Slice(d.Added, func(i, j int) bool { return nameOf(d.Added[i].NewNames) < nameOf(d.Added[j].NewNames) })
+	sort.Slice(d.Removed, func(i, j int) bool { return nameOf(d.Removed[i].OldNames) < nameOf(d.Removed[j].OldNames) })
+	sort.Slice(d.Changed, func(i, j int) bool { return nameOf(d.Changed[i].NewNames) < nameOf(d.Changed[j].NewNames) })
+
+	return d, nil
+}
+
+// realEntry returns a function unwrapping entries by validators of cluster.
+func realEntry(c *Cluster) func(*KeyValue) *KeyValue {
+	return func(kv *KeyValue) *KeyValue {
+		c.lock.RLock()
+		defer c.lock.RUnlock()
+		return c.getRealEntry(kv)
+	}
+}
+
+// DiffCluster compares views of two clusters. Nodes are matched by name resolver of the old one.
+// Entries are compared by real values unwrapped by validators of each cluster.
+func DiffCluster(old, new *Cluster) (*SnapshotDiff, error) {
+	var oldSnap, newSnap proto.Cluster
+	old.ProtobufSnapshot(&oldSnap, nil)
+	new.ProtobufSnapshot(&newSnap, nil)
+	return diffSnapshot(&oldSnap, &newSnap, old.resolver, realEntry(old), realEntry(new))
+}
@@ -0,0 +1,168 @@
+package sladder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/crossmesh/sladder/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSnapshot(t *testing.T) {
+	r := &TestNamesInKeyNameResolver{Key: "id"}
+	node := func(names string, kvs ...string) *proto.Node {
+		n := &proto.Node{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: `{"ns":[` + names + `]}`}}}
+		for i := 0; i+1 < len(kvs); i += 2 {
+			n.Kvs = append(n.Kvs, &proto.Node_KeyValue{Key: kvs[i], Value: kvs[i+1]})
+		}
+		return n
+	}
+
+	old := &proto.Cluster{Nodes: []*proto.Node{
+		node(`"n1"`, "k1", "a", "k2", "b"),
+		node(`"n2"`, "k1", "a"),
+		node(`"n3"`),
+	}}
+	new := &proto.Cluster{Nodes: []*proto.Node{
+		node(`"n3"`),
+		node(`"n1","n1b"`, "k1", "c", "k3", "d"),
+		node(`"n4"`, "k1", "a"),
+	}}
+
+	d, err := DiffSnapshot(old, old, r)
+	assert.NoError(t, err)
+	assert.True(t, d.Empty())
+
+	d, err = DiffSnapshot(old, new, r)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.False(t, d.Empty())
+	if assert.Equal(t, 1, len(d.Added)) {
+		assert.Equal(t, []string{"n4"}, d.Added[0].NewNames)
+		assert.Equal(t, 2, len(d.Added[0].Inserted))
+	}
+	if assert.Equal(t, 1, len(d.Removed)) {
+		assert.Equal(t, []string{"n2"}, d.Removed[0].OldNames)
+		assert.Equal(t, 2, len(d.Removed[0].Deleted))
+	}
+	if assert.Equal(t, 1, len(d.Changed)) {
+		c := d.Changed[0]
+		assert.True(t, c.NameChanged())
+		assert.Equal(t, []string{"n1"}, c.OldNames)
+		assert.Equal(t, []string{"n1", "n1b"}, c.NewNames)
+		assert.Equal(t, []*KeyValue{{Key: "k3", Value: "d"}}, c.Inserted)
+		assert.Equal(t, []*KeyValue{{Key: "k2", Value: "b"}}, c.Deleted)
+		assert.Equal(t, 2, len(c.Changed)) // id and k1.
+		assert.Equal(t, &KeyValueChange{Key: "k1", Old: "a", New: "c", OldRaw: "a", NewRaw: "c"}, c.Changed[1])
+	}
+	t.Log("\n" + d.String())
+
+	_, err = DiffSnapshot(old, new, nil)
+	assert.Equal(t, ErrMissingNameResolver, err)
+
+	t.Run("unnamed", func(t *testing.T) {
+		unnamed := func(kvs ...string) *proto.Node {
+			n := &proto.Node{}
+			for i := 0; i+1 < len(kvs); i += 2 {
+				n.Kvs = append(n.Kvs, &proto.Node_KeyValue{Key: kvs[i], Value: kvs[i+1]})
+			}
+			return n
+		}
+		old := &proto.Cluster{Nodes: []*proto.Node{unnamed("k1", "a"), unnamed("k1", "b")}}
+		new := &proto.Cluster{Nodes: []*proto.Node{unnamed("k1", "b"), unnamed("k1", "c")}}
+
+		d, err := DiffSnapshot(old, new, r)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(d.Changed))
+		if assert.Equal(t, 1, len(d.Added)) {
+			assert.Equal(t, []*KeyValue{{Key: "k1", Value: "c"}}, d.Added[0].Inserted)
+		}
+		if assert.Equal(t, 1, len(d.Removed)) {
+			assert.Equal(t, []*KeyValue{{Key: "k1", Value: "a"}}, d.Removed[0].Deleted)
+		}
+	})
+}
+
+// versionedTestValidator stores values in format of "<version>:<value>".
+type versionedTestValidator struct{}
+
+func (v versionedTestValidator) Sync(lr, rr *KeyValue) (bool, error) {
+	if lr == nil || rr == nil {
+		return lr != nil, nil
+	}
+	lr.Value = rr.Value
+	return true, nil
+}
+
+func (v versionedTestValidator) Validate(KeyValue) bool { return true }
+
+func (v versionedTestValidator) Txn(x KeyValue) (KVTransaction, error) {
+	txn := &versionedTestTxn{}
+	return txn, txn.SetRawValue(x.Value)
+}
+
+type versionedTestTxn struct {
+	version string
+	real    *StringTxn
+}
+
+func (t *versionedTestTxn) Updated() bool                { return t.real.Updated() }
+func (t *versionedTestTxn) After() string                { return t.version + ":" + t.real.After() }
+func (t *versionedTestTxn) Before() string               { return t.version + ":" + t.real.Before() }
+func (t *versionedTestTxn) KVTransaction() KVTransaction { return t.real }
+func (t *versionedTestTxn) SetRawValue(x string) error {
+	t.version = "0"
+	if idx := strings.Index(x, ":"); idx >= 0 {
+		t.version, x = x[:idx], x[idx+1:]
+	}
+	if t.real == nil {
+		t.real = &StringTxn{origin: x}
+	}
+	t.real.Set(x)
+	return nil
+}
+
+func TestDiffCluster(t *testing.T) {
+	newCluster := func(nodes ...*proto.Node) *Cluster {
+		c := newTestSnapshotCluster(t)
+		assert.NoError(t, c.RegisterKey("id", &StringValidator{}, false, 0))
+		assert.NoError(t, c.RegisterKey("key1", &StringValidator{}, false, 0))
+		assert.NoError(t, c.ApplySnapshot(&proto.Cluster{Nodes: nodes}))
+		return c
+	}
+	versioned := func(nodes ...*proto.Node) *Cluster {
+		c := newCluster()
+		assert.NoError(t, c.RegisterKey("key2", versionedTestValidator{}, false, 0))
+		assert.NoError(t, c.ApplySnapshot(&proto.Cluster{Nodes: nodes}))
+		return c
+	}
+	c1 := newCluster(
+		&proto.Node{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: "n1"}, {Key: "key1", Value: "a"}}},
+	)
+	c2 := newCluster(
+		&proto.Node{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: "n1"}, {Key: "key1", Value: "b"}}},
+	)
+	d, err := DiffCluster(c1, c1)
+	assert.NoError(t, err)
+	assert.True(t, d.Empty())
+
+	d, err = DiffCluster(c1, c2)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(d.Changed)) {
+		assert.Equal(t, []*KeyValueChange{{Key: "key1", Old: "a", New: "b", OldRaw: "a", NewRaw: "b"}}, d.Changed[0].Changed)
+	}
+
+	// compared by real values.
+	v1 := versioned(&proto.Node{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: "n1"}, {Key: "key2", Value: "1:a"}}})
+	v2 := versioned(&proto.Node{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: "n1"}, {Key: "key2", Value: "2:a"}}})
+	v3 := versioned(&proto.Node{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: "n1"}, {Key: "key2", Value: "3:b"}}})
+	d, err = DiffCluster(v1, v2)
+	assert.NoError(t, err)
+	assert.True(t, d.Empty())
+	d, err = DiffCluster(v1, v3)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(d.Changed)) {
+		assert.Equal(t, []*KeyValueChange{{Key: "key2", Old: "a", New: "b", OldRaw: "1:a", NewRaw: "3:b"}}, d.Changed[0].Changed)
+	}
+}
@@ -0,0 +1,118 @@
+package gossip
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/crossmesh/sladder/engine/gossip/pb"
+)
+
+const (
+	defaultCompressThreshold = 512
+	maxDecompressedSize      = 32 * 1024 * 1024
+)
+
+var (
+	ErrUnknownCodec         = errors.New("unknown codec")
+	ErrDecompressedOversize = errors.New("decompressed message too large")
+)
+
+var flateWriterPool = sync.Pool{}
+
+func compressFlate(raw []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, len(raw)/2))
+
+	w, _ := flateWriterPool.Get().(*flate.Writer)
+	if w == nil {
+		var err error
+		if w, err = flate.NewWriter(buf, flate.DefaultCompression); err != nil {
+			return nil, err
+		}
+	} else {
+		w.Reset(buf)
+	}
+	defer flateWriterPool.Put(w)
+
+	if _, err := w.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressFlate(raw []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(raw))
+	defer r.Close()
+
+	out, err := ioutil.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > maxDecompressedSize {
+		return nil, ErrDecompressedOversize
+	}
+	return out, nil
+}
+
+// compressMessage compresses body of message if it is large enough and compression saves bytes.
+func (e *EngineInstance) compressMessage(msg *pb.GossipMessage) {
+	if !e.compression || msg.Body == nil {
+		return
+	}
+	raw := msg.Body.Value
+	if len(raw) < e.compressThreshold {
+		return
+	}
+	compressed, err := compressFlate(raw)
+	if err != nil {
+		e.log.Warnf("failed to compress gossip message. (err = \"%v\")", err)
+		return
+	}
+	if len(compressed) >= len(raw) {
+		return
+	}
+
+	msg.Body.Value, msg.Codec = compressed, pb.GossipMessage_Flate
+
+	e.Metrics.Compression.ApplyIncrement(&CompressionMetricIncrement{
+		Compressed:        1,
+		UncompressedBytes: uint64(len(raw)),
+		CompressedBytes:   uint64(len(compressed)),
+	})
+}
+
+// decompressMessage restores body of message according to its codec.
+func (e *EngineInstance) decompressMessage(msg *pb.GossipMessage) (err error) {
+	switch msg.Codec {
+	case pb.GossipMessage_Raw:
+		return nil
+
+	case pb.GossipMessage_Flate:
+		if msg.Body == nil {
+			return nil
+		}
+		var raw []byte
+		if raw, err = decompressFlate(msg.Body.Value); err != nil {
+			break
+		}
+		e.Metrics.Compression.ApplyIncrement(&CompressionMetricIncrement{
+			Decompressed:              1,
+			IncomingCompressedBytes:   uint64(len(msg.Body.Value)),
+			IncomingUncompressedBytes: uint64(len(raw)),
+		})
+		msg.Body.Value, msg.Codec = raw, pb.GossipMessage_Raw
+		return nil
+
+	default:
+		err = ErrUnknownCodec
+	}
+
+	e.Metrics.Compression.ApplyIncrement(&CompressionMetricIncrement{DecompressFailure: 1})
+	return err
+}
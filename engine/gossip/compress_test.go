@@ -0,0 +1,152 @@
+package gossip
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/crossmesh/sladder"
+	"github.com/crossmesh/sladder/engine/gossip/pb"
+	"github.com/crossmesh/sladder/engine/gossip/simnet"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCompression(t *testing.T) {
+	tp := simnet.New().Transport("default")
+
+	t.Run("flate", func(t *testing.T) {
+		raw := bytes.Repeat([]byte("{\"v\":1,\"s\":0}"), 100)
+		for i := 0; i < 2; i++ { // writer reused.
+			compressed, err := compressFlate(raw)
+			assert.NoError(t, err)
+			assert.Less(t, len(compressed), len(raw))
+			decompressed, err := decompressFlate(compressed)
+			assert.NoError(t, err)
+			assert.Equal(t, raw, decompressed)
+		}
+
+		_, err := decompressFlate([]byte("not flate"))
+		assert.Error(t, err)
+
+		bomb, err := compressFlate(make([]byte, maxDecompressedSize+1))
+		assert.NoError(t, err)
+		_, err = decompressFlate(bomb)
+		assert.Equal(t, ErrDecompressedOversize, err)
+	})
+
+	t.Run("message", func(t *testing.T) {
+		e := New(tp, WithCompression(0)).(*EngineInstance)
+		assert.True(t, e.compression)
+		assert.Equal(t, defaultCompressThreshold, e.compressThreshold)
+		e = New(tp, WithCompression(64)).(*EngineInstance)
+		assert.Equal(t, 64, e.compressThreshold)
+
+		raw := bytes.Repeat([]byte("a"), 128)
+		msg := &pb.GossipMessage{Body: &any.Any{Value: raw[:63]}}
+		e.compressMessage(msg)
+		assert.Equal(t, pb.GossipMessage_Raw, msg.Codec)
+		assert.Equal(t, raw[:63], msg.Body.Value)
+
+		msg.Body.Value = raw
+		e.compressMessage(msg)
+		assert.Equal(t, pb.GossipMessage_Flate, msg.Codec)
+		assert.Less(t, len(msg.Body.Value), len(raw))
+		assert.Equal(t, uint64(1), e.Metrics.Compression.Compressed)
+		assert.Equal(t, uint64(len(raw)), e.Metrics.Compression.UncompressedBytes)
+		assert.Equal(t, uint64(len(msg.Body.Value)), e.Metrics.Compression.CompressedBytes)
+
+		// uncompressible.
+		incompressible := make([]byte, 128)
+		for i := range incompressible {
+			incompressible[i] = byte(i * 97)
+		}
+		msg2 := &pb.GossipMessage{Body: &any.Any{Value: incompressible}}
+		e.compressMessage(msg2)
+		if msg2.Codec == pb.GossipMessage_Raw {
+			assert.Equal(t, incompressible, msg2.Body.Value)
+		}
+
+		// decode on another engine without compression enabled.
+		r := New(tp).(*EngineInstance)
+		assert.NoError(t, r.decompressMessage(msg))
+		assert.Equal(t, pb.GossipMessage_Raw, msg.Codec)
+		assert.Equal(t, raw, msg.Body.Value)
+		assert.Equal(t, uint64(1), r.Metrics.Compression.Decompressed)
+		assert.NoError(t, r.decompressMessage(msg))
+
+		msg.Codec = pb.GossipMessage_Flate
+		assert.Error(t, r.decompressMessage(msg))
+		msg.Codec = pb.GossipMessage_Codec(100)
+		assert.Equal(t, ErrUnknownCodec, r.decompressMessage(msg))
+		assert.Equal(t, uint64(2), r.Metrics.Compression.DecompressFailure)
+	})
+
+	t.Run("wire", func(t *testing.T) {
+		e := New(tp, WithCompression(1)).(*EngineInstance)
+		req := &pb.PingReq{Id: 1}
+		for i := 0; i < 64; i++ {
+			req.Name = append(req.Name, "node-"+strconv.FormatInt(int64(i), 10))
+		}
+		body, err := ptypes.MarshalAny(req)
+		assert.NoError(t, err)
+		msg := &pb.GossipMessage{Type: pb.GossipMessage_PingReq, Body: body}
+		e.compressMessage(msg)
+		assert.Equal(t, pb.GossipMessage_Flate, msg.Codec)
+		raw, err := proto.Marshal(msg)
+		assert.NoError(t, err)
+
+		var decoded pb.GossipMessage
+		assert.NoError(t, proto.Unmarshal(raw, &decoded))
+		assert.NoError(t, e.decompressMessage(&decoded))
+		var decodedReq pb.PingReq
+		assert.NoError(t, ptypes.UnmarshalAny(decoded.Body, &decodedReq))
+		assert.Equal(t, req.Id, decodedReq.Id)
+		assert.Equal(t, req.Name, decodedReq.Name)
+	})
+
+	t.Run("mixed_cluster", func(t *testing.T) {
+		god, ctl, err := newClusterGod("tst", 1, 6, nil, nil)
+		assert.NoError(t, err)
+		defer god.Detach(ctl)
+		vps := god.VPList()
+		for idx, vp := range vps {
+			if idx%2 == 0 {
+				vp.engine.compression, vp.engine.compressThreshold = true, 1
+			}
+		}
+		for i := 1; i < len(vps); i++ {
+			vp, nvp := vps[i-1], vps[i]
+			n, err := vp.cv.NewNode()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.NoError(t, vp.cv.Txn(func(t *sladder.Transaction) bool {
+				rtx, err := t.KV(n, "idkey")
+				if err != nil {
+					return false
+				}
+				rtx.(*sladder.TestNamesInKeyTxn).AddName(nvp.cv.Self().Names()...)
+				return true
+			}))
+		}
+		consistAt := syncLoop(t, vps, 500, func(round int) bool {
+			return !god.AllViewpointConsist(true, true)
+		}, false, false, false, nil)
+		assert.Less(t, consistAt, 500, "node entry cannot be consist within 500 round.")
+
+		for idx, vp := range vps {
+			m := &vp.engine.Metrics.Compression
+			m.lock.Lock()
+			if idx%2 == 0 {
+				assert.Greater(t, m.Compressed, uint64(0))
+				assert.Less(t, m.CompressedBytes, m.UncompressedBytes)
+			} else {
+				assert.Equal(t, uint64(0), m.Compressed)
+			}
+			m.lock.Unlock()
+		}
+	})
+}
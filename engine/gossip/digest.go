@@ -0,0 +1,200 @@
+package gossip
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"sort"
+
+	"github.com/crossmesh/sladder"
+	pb "github.com/crossmesh/sladder/engine/gossip/pb"
+	"github.com/crossmesh/sladder/proto"
+)
+
+// SyncMode specifies the way to synchronize cluster states.
+type SyncMode uint8
+
+const (
+	// FullSync sends entire cluster snapshot in each sync.
+	FullSync = SyncMode(iota)
+	// DigestSync sends node digests first, then only nodes that differ are exchanged.
+	DigestSync
+)
+
+func (m SyncMode) String() string {
+	switch m {
+	case FullSync:
+		return "full"
+	case DigestSync:
+		return "digest"
+	}
+	return "unknown"
+}
+
+// nodeSnapshotHash hashes entries of node snapshot regardless of entry order.
+func nodeSnapshotHash(msg *proto.Node) uint64 {
+	kvs := make([]*proto.Node_KeyValue, len(msg.Kvs))
+	copy(kvs, msg.Kvs)
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+
+	h, buf := fnv.New64a(), make([]byte, binary.MaxVarintLen64)
+	write := func(s string) {
+		n := binary.PutUvarint(buf, uint64(len(s)))
+		h.Write(buf[:n])
+		io.WriteString(h, s)
+	}
+	for _, kv := range kvs {
+		write(kv.Key)
+		write(kv.Value)
+	}
+	return h.Sum64()
+}
+
+func (e *EngineInstance) newNodeDigest(names []string, msg *proto.Node) *pb.NodeDigest {
+	digest := &pb.NodeDigest{
+		Names: names,
+		Hash:  nodeSnapshotHash(msg),
+	}
+	for _, kv := range msg.Kvs {
+		if kv.Key != e.swimTagKey {
+			continue
+		}
+		tag := &SWIMTag{}
+		if err := tag.Decode(kv.Value); err == nil {
+			digest.Version, digest.Generation = tag.Version, tag.Generation
+		}
+		break
+	}
+	return digest
+}
+
+// digestTag returns SWIM tag with versioning fields in digest, for comparing digests by SWIMTag.Succeeds.
+func digestTag(digest *pb.NodeDigest) *SWIMTag {
+	return &SWIMTag{Version: digest.Version, Generation: digest.Generation}
+}
+
+// syncDigestSet contains cluster snapshot and digests of snapshot nodes.
+type syncDigestSet struct {
+	snap    *proto.Cluster
+	digests []*pb.NodeDigest
+	index   map[string]int // name --> index of node.
+}
+
+func (e *EngineInstance) newSyncDigestSet(t *sladder.Transaction) *syncDigestSet {
+	snap, names := e.newSyncClusterSnapshotWithNames(t)
+	sortSnapshotNodes(snap.Nodes, names)
+
+	s := &syncDigestSet{
+		snap:    snap,
+		digests: make([]*pb.NodeDigest, 0, len(snap.Nodes)),
+		index:   make(map[string]int),
+	}
+	for idx, node := range snap.Nodes {
+		s.digests = append(s.digests, e.newNodeDigest(names[idx], node))
+		for _, name := range names[idx] {
+			s.index[name] = idx
+		}
+	}
+	return s
+}
+
+func (s *syncDigestSet) lookup(names []string) int {
+	for _, name := range names {
+		if idx, exists := s.index[name]; exists {
+			return idx
+		}
+	}
+	return -1
+}
+
+// pages splits digests into pages within limit bytes. Digest at index pin is included in every page.
+func (s *syncDigestSet) pages(pin, limit int) [][]*pb.NodeDigest {
+	if pin < 0 || pin >= len(s.digests) {
+		return paginateDigests(s.digests, limit, 0)
+	}
+	pinned := s.digests[pin]
+	others := make([]*pb.NodeDigest, 0, len(s.digests)-1)
+	others = append(others, s.digests[:pin]...)
+	others = append(others, s.digests[pin+1:]...)
+
+	pages := paginateDigests(others, limit, encodedSizeOfField(pinned))
+	if len(pages) < 1 {
+		return [][]*pb.NodeDigest{{pinned}}
+	}
+	for idx, page := range pages {
+		pages[idx] = append([]*pb.NodeDigest{pinned}, page...)
+	}
+	return pages
+}
+
+// diff compares remote digests with local ones.
+// It returns local nodes which are missing or different in remote, and remote digests which are missing or different in local.
+// Remote digests with older SWIM tags are not wanted, since the remote copies are outdated.
+// For partial remote digests, local nodes missing in remote are not returned.
+func (s *syncDigestSet) diff(remote []*pb.NodeDigest, partial bool) (nodes []*proto.Node, wanted []*pb.NodeDigest) {
+	matched := make([]bool, len(s.digests))
+
+	for _, digest := range remote {
+		idx := s.lookup(digest.Names)
+		if idx < 0 {
+			wanted = append(wanted, digest)
+			continue
+		}
+		if local := s.digests[idx]; local.Hash != digest.Hash {
+			if !digestTag(local).Succeeds(digestTag(digest)) {
+				wanted = append(wanted, digest)
+			}
+			if !matched[idx] {
+				nodes = append(nodes, s.snap.Nodes[idx])
+			}
+		}
+		matched[idx] = true
+	}
+	if partial {
+		return
+	}
+	for idx, digest := range s.digests {
+		if !matched[idx] && len(digest.Names) > 0 {
+			nodes = append(nodes, s.snap.Nodes[idx])
+		}
+	}
+
+	return
+}
+
+// selectNodes returns local nodes for digests.
+func (s *syncDigestSet) selectNodes(digests []*pb.NodeDigest) (nodes []*proto.Node) {
+	selected := make(map[int]struct{}, len(digests))
+	for _, digest := range digests {
+		idx := s.lookup(digest.Names)
+		if idx < 0 {
+			continue
+		}
+		if _, dup := selected[idx]; dup {
+			continue
+		}
+		selected[idx] = struct{}{}
+		nodes = append(nodes, s.snap.Nodes[idx])
+	}
+	return
+}
+
+func (e *EngineInstance) processSyncDigest(from []string, sync *pb.Sync, minc *SyncMetricIncrement) {
+	var (
+		nodes  []*proto.Node
+		wanted []*pb.NodeDigest
+	)
+
+	if err := e.cluster.Txn(func(t *sladder.Transaction) bool {
+		nodes, wanted = e.newSyncDigestSet(t).diff(sync.Digests, sync.Pages > 1)
+		return false
+	}, sladder.MembershipModification()); err != nil {
+		e.log.Warnf("a sync failure raised. %v", err.Error())
+		return
+	}
+
+	// always reply so that the remote knows whether its own node is seen.
+	pages := e.sendSyncPages(from, sync.Id, pb.Sync_DigestPushPull, pb.Sync_DigestPush, nodes, wanted)
+	minc.PushPull++
+	minc.Push += uint64(pages - 1)
+}
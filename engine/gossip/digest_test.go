@@ -0,0 +1,211 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	pb "github.com/crossmesh/sladder/engine/gossip/pb"
+	"github.com/crossmesh/sladder/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeSnapshotHash(t *testing.T) {
+	n1 := &proto.Node{Kvs: []*proto.Node_KeyValue{
+		{Key: "a", Value: "1"}, {Key: "b", Value: "2"},
+	}}
+	n2 := &proto.Node{Kvs: []*proto.Node_KeyValue{
+		{Key: "b", Value: "2"}, {Key: "a", Value: "1"},
+	}}
+	n3 := &proto.Node{Kvs: []*proto.Node_KeyValue{
+		{Key: "a", Value: "12"}, {Key: "b", Value: ""},
+	}}
+	assert.Equal(t, nodeSnapshotHash(n1), nodeSnapshotHash(n2))
+	assert.NotEqual(t, nodeSnapshotHash(n1), nodeSnapshotHash(n3))
+	assert.Equal(t, "b", n2.Kvs[0].Key) // not reordered.
+
+	e := newInstanceDefault(nil)
+	tag := &SWIMTag{Version: 3, Generation: 5}
+	d := e.newNodeDigest([]string{"n1"}, &proto.Node{Kvs: []*proto.Node_KeyValue{
+		{Key: "a", Value: "1"}, {Key: e.swimTagKey, Value: tag.Encode()},
+	}})
+	assert.Equal(t, []string{"n1"}, d.Names)
+	assert.Equal(t, uint32(3), d.Version)
+	assert.Equal(t, uint64(5), d.Generation)
+}
+
+func TestSyncDigestSet(t *testing.T) {
+	nodes := []*proto.Node{
+		{Kvs: []*proto.Node_KeyValue{{Key: "v", Value: "1"}}},
+		{Kvs: []*proto.Node_KeyValue{{Key: "v", Value: "2"}}},
+		{Kvs: []*proto.Node_KeyValue{{Key: "v", Value: "3"}}},
+		{Kvs: []*proto.Node_KeyValue{{Key: "v", Value: "4"}}}, // anonymous.
+	}
+	names := [][]string{{"n1", "n1-1"}, {"n2"}, {"n3"}, nil}
+	s := &syncDigestSet{
+		snap:  &proto.Cluster{Nodes: nodes},
+		index: make(map[string]int),
+	}
+	e := newInstanceDefault(nil)
+	for idx, node := range nodes {
+		s.digests = append(s.digests, e.newNodeDigest(names[idx], node))
+		for _, name := range names[idx] {
+			s.index[name] = idx
+		}
+	}
+
+	// identical.
+	diffNodes, wanted := s.diff(s.digests[:3], false)
+	assert.Equal(t, 0, len(diffNodes))
+	assert.Equal(t, 0, len(wanted))
+
+	remote := []*pb.NodeDigest{
+		{Names: []string{"n1-1"}, Hash: s.digests[0].Hash},   // same.
+		{Names: []string{"n2"}, Hash: s.digests[1].Hash + 1}, // differs.
+		{Names: []string{"n4"}, Hash: 1},                     // missing in local.
+		{Names: []string{"n2", "n5"}, Hash: 2},               // differs, matching the same local node.
+	}
+	diffNodes, wanted = s.diff(remote, false)
+	assert.Equal(t, []*proto.Node{nodes[1], nodes[2]}, diffNodes) // n3 is missing in remote.
+	assert.Equal(t, []*pb.NodeDigest{remote[1], remote[2], remote[3]}, wanted)
+	diffNodes, wanted = s.diff(remote, true)
+	assert.Equal(t, []*proto.Node{nodes[1]}, diffNodes)
+	assert.Equal(t, []*pb.NodeDigest{remote[1], remote[2], remote[3]}, wanted)
+
+	assert.Equal(t, []*proto.Node{nodes[0], nodes[1]}, s.selectNodes(remote))
+
+	// outdated remote node is pushed, but not wanted.
+	s.digests[1].Version = 2
+	diffNodes, wanted = s.diff([]*pb.NodeDigest{{Names: []string{"n2"}, Version: 1, Hash: 1}}, true)
+	assert.Equal(t, []*proto.Node{nodes[1]}, diffNodes)
+	assert.Equal(t, 0, len(wanted))
+	diffNodes, wanted = s.diff([]*pb.NodeDigest{{Names: []string{"n2"}, Version: 3, Hash: 1}}, true)
+	assert.Equal(t, []*proto.Node{nodes[1]}, diffNodes)
+	assert.Equal(t, 1, len(wanted))
+
+	// restarted remote node has a later generation with a lower version.
+	s.digests[1].Generation = 1
+	diffNodes, wanted = s.diff([]*pb.NodeDigest{{Names: []string{"n2"}, Version: 1, Generation: 2, Hash: 1}}, true)
+	assert.Equal(t, []*proto.Node{nodes[1]}, diffNodes)
+	assert.Equal(t, 1, len(wanted))
+	s.digests[1].Generation = 2
+	diffNodes, wanted = s.diff([]*pb.NodeDigest{{Names: []string{"n2"}, Version: 3, Generation: 1, Hash: 1}}, true)
+	assert.Equal(t, []*proto.Node{nodes[1]}, diffNodes)
+	assert.Equal(t, 0, len(wanted)) // copy of former generation.
+}
+
+func TestDigestSync(t *testing.T) {
+	god, ctl, err := newHealthyClusterGod(t, "tst", 2, 10, []sladder.EngineOption{WithSyncMode(DigestSync)}, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, ctl)
+	defer god.Detach(ctl)
+	if !assert.True(t, god.AllViewpointConsist(true, true)) {
+		t.FailNow()
+	}
+
+	vps := god.VPList()
+	for _, vp := range vps {
+		assert.Equal(t, DigestSync, vp.engine.syncMode)
+		vp.engine.Metrics.Sync.lock.Lock()
+		assert.Greater(t, vp.engine.Metrics.Sync.Digest, uint64(0))
+		assert.Equal(t, vp.engine.Metrics.Sync.IncomingDigest, vp.engine.Metrics.Sync.PushPull) // every digest is replied.
+		vp.engine.Metrics.Sync.lock.Unlock()
+	}
+
+	// consist cluster has nothing to exchange.
+	digestsOf := func(vp *testClusterViewPoint) (s *syncDigestSet) {
+		vp.cv.Txn(func(t *sladder.Transaction) bool {
+			s = vp.engine.newSyncDigestSet(t)
+			return false
+		}, sladder.MembershipModification())
+		return
+	}
+	nodes, wanted := digestsOf(vps[0]).diff(digestsOf(vps[1]).digests, false)
+	assert.Equal(t, 0, len(nodes))
+	assert.Equal(t, 0, len(wanted))
+
+	// only changed node is exchanged.
+	for _, vp := range vps {
+		m := vp.engine.WrapVersionKVValidator(sladder.StringValidator{})
+		assert.NoError(t, vp.cv.RegisterKey("key1", m, true, 0))
+	}
+	assert.NoError(t, vps[0].cv.Txn(func(tx *sladder.Transaction) bool {
+		rtx, err := tx.KV(vps[0].cv.Self(), "key1")
+		if !assert.NoError(t, err) {
+			return false
+		}
+		rtx.(*sladder.StringTxn).Set("1")
+		return true
+	}))
+	nodes, wanted = digestsOf(vps[1]).diff(digestsOf(vps[0]).digests, false)
+	assert.Equal(t, 1, len(nodes))
+	if assert.Equal(t, 1, len(wanted)) {
+		assert.Equal(t, vps[0].cv.Self().Names(), wanted[0].Names)
+	}
+	// the remote copy of the changed node is outdated, so it is not wanted.
+	nodes, wanted = digestsOf(vps[0]).diff(digestsOf(vps[1]).digests, false)
+	assert.Equal(t, 1, len(nodes))
+	assert.Equal(t, 0, len(wanted))
+
+	consistAt := syncLoop(t, vps, 500, func(round int) bool {
+		return !god.AllViewpointConsist(true, true)
+	}, false, false, false, nil)
+	assert.Less(t, consistAt, 500, "node entry cannot be consist within 500 round.")
+	t.Log("cluster is consist at round", consistAt)
+}
+
+func TestDigestSyncQuit(t *testing.T) {
+	god, ctl, err := newHealthyClusterGod(t, "tst", 1, 2, []sladder.EngineOption{
+		WithSyncMode(DigestSync),
+		WithGossipPeriod(time.Millisecond * 50),
+	}, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, ctl)
+	defer god.Detach(ctl)
+
+	vps := god.VPList()
+	quitVP, peer := vps[0], vps[1]
+	selfTagState := func(vp *testClusterViewPoint, names []string) (state SWIMState) {
+		vp.cv.Txn(func(tx *sladder.Transaction) bool {
+			node := tx.MostPossibleNode(names)
+			if node == nil {
+				state = DEAD // removed.
+				return false
+			}
+			rtx, err := tx.KV(node, vp.engine.swimTagKey)
+			if assert.NoError(t, err) {
+				state = rtx.(*SWIMTagTxn).State()
+			}
+			return false
+		})
+		return
+	}
+
+	quitVP.engine.ClusterSync()
+	assert.Eventually(t, func() bool { return !quitVP.engine.canQuitTrivial() }, time.Second, time.Millisecond*10)
+
+	quited := make(chan struct{})
+	go func() {
+		assert.NoError(t, quitVP.cv.Quit())
+		close(quited)
+	}()
+	names := quitVP.cv.Self().Names()
+	assert.Eventually(t, func() bool { return selfTagState(quitVP, names) == LEFT }, time.Second, time.Millisecond*10)
+
+	// the peer holds the old version of myself, but it still knows myself.
+	quitVP.engine.ClusterSync()
+	time.Sleep(time.Millisecond * 100)
+	assert.False(t, quitVP.engine.canQuitTrivial())
+	assert.NotEqual(t, ALIVE, selfTagState(peer, names)) // LEFT state is pushed, so the peer may have removed myself.
+
+	// quit after the peer is known to have LEFT state.
+	assert.Eventually(t, func() bool {
+		select {
+		case <-quited:
+			return true
+		default:
+		}
+		quitVP.engine.ClusterSync()
+		return false
+	}, time.Second*3, time.Millisecond*100)
+}
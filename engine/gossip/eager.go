@@ -0,0 +1,82 @@
+package gossip
+
+import (
+	"time"
+
+	"github.com/crossmesh/sladder"
+	pb "github.com/crossmesh/sladder/engine/gossip/pb"
+	"github.com/crossmesh/sladder/proto"
+)
+
+const defaultEagerPushInterval = time.Millisecond * 100
+
+// traceLocalChanges schedules an eager push when user transaction modifies self.
+// Eager push is a kind of sync, so it is skipped when auto sync is disabled.
+func (e *EngineInstance) traceLocalChanges(t *sladder.Transaction, isEngineTxn bool, ops []*sladder.TransactionOperation) (bool, error) {
+	if isEngineTxn || e.disableSync || e.eagerPushInterval <= 0 {
+		return true, nil
+	}
+
+	self := e.cluster.Self()
+	for _, op := range ops {
+		if op.Txn == nil || op.Node != self {
+			continue
+		}
+		if op.Updated || op.PastExists != op.Exists {
+			t.DeferOnCommit(e.scheduleEagerPush)
+			break
+		}
+	}
+
+	return true, nil
+}
+
+// scheduleEagerPush schedules an eager push, which is delayed to the end of interval since the last one.
+func (e *EngineInstance) scheduleEagerPush() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.eagerPushPending {
+		return // changes will be included in the pending one.
+	}
+	e.eagerPushPending = true
+
+	if delay := time.Until(e.lastEagerPush.Add(e.eagerPushInterval)); delay > 0 {
+		time.AfterFunc(delay, e.eagerPush)
+	} else {
+		e.arbiter.Go(e.eagerPush)
+	}
+}
+
+// eagerPush pushes self to peers.
+func (e *EngineInstance) eagerPush() {
+	e.lock.Lock()
+	e.eagerPushPending, e.lastEagerPush = false, time.Now()
+	e.lock.Unlock()
+
+	if !e.arbiter.ShouldRun() {
+		return
+	}
+
+	var nodes [][]string
+	snap := &proto.Node{}
+	e.cluster.Txn(func(t *sladder.Transaction) bool {
+		t.ReadNodeSnapshot(e.cluster.Self(), snap)
+		return false
+	})
+	for _, node := range e.selectRandomNodes(e.getGossipFanout(), true) {
+		if names := node.Names(); len(names) > 0 {
+			nodes = append(nodes, names)
+		}
+	}
+
+	minc := &SyncMetricIncrement{}
+	defer e.Metrics.Sync.ApplyIncrement(minc)
+	for _, names := range nodes {
+		e.lock.Lock()
+		id := e._generateMessageID()
+		e.lock.Unlock()
+
+		minc.EagerPush += uint64(e.sendSyncPages(names, id, pb.Sync_Eager, pb.Sync_Eager, []*proto.Node{snap}, nil))
+	}
+}
@@ -0,0 +1,125 @@
+package gossip
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEagerPush(t *testing.T) {
+	setupCluster := func(t *testing.T, manualSync bool, options ...sladder.EngineOption) (*testClusterGod, []*testClusterViewPoint, func()) {
+		god, ctl, err := newHealthyClusterGod(t, "eager-tst", 1, 4,
+			append([]sladder.EngineOption{WithGossipPeriod(time.Second), WithFanout(3)}, options...), nil)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		vps := god.VPList()
+		for _, vp := range vps {
+			// periodic sync is never started by the god. the flag only switches eager push here.
+			vp.engine.disableSync = manualSync
+			m := vp.engine.WrapVersionKVValidator(sladder.StringValidator{})
+			assert.NoError(t, vp.cv.RegisterKey("key1", m, true, 0))
+		}
+		time.Sleep(time.Millisecond * 300) // wait for eager pushes of cluster setup.
+		return god, vps, func() { god.Detach(ctl) }
+	}
+	set := func(t *testing.T, vp *testClusterViewPoint, value string) {
+		assert.NoError(t, vp.cv.Txn(func(tx *sladder.Transaction) bool {
+			rtx, err := tx.KV(vp.cv.Self(), "key1")
+			if !assert.NoError(t, err) {
+				return false
+			}
+			rtx.(*sladder.StringTxn).Set(value)
+			return true
+		}))
+	}
+	seenBy := func(vp *testClusterViewPoint, names []string, value string) (seen bool) {
+		vp.cv.Txn(func(tx *sladder.Transaction) bool {
+			node := tx.MostPossibleNode(names)
+			if node == nil || !tx.KeyExists(node, "key1") {
+				return false
+			}
+			rtx, err := tx.KV(node, "key1")
+			if err == nil {
+				seen = rtx.(*sladder.StringTxn).Get() == value
+			}
+			return false
+		})
+		return
+	}
+	eagerPushes := func(vp *testClusterViewPoint) uint64 {
+		vp.engine.Metrics.Sync.lock.Lock()
+		defer vp.engine.Metrics.Sync.lock.Unlock()
+		return vp.engine.Metrics.Sync.EagerPush
+	}
+
+	t.Run("push", func(t *testing.T) {
+		_, vps, detach := setupCluster(t, false)
+		defer detach()
+
+		// no cluster sync. changes spread by eager pushes.
+		src := vps[0]
+		before := eagerPushes(src)
+		set(t, src, "1")
+		assert.Eventually(t, func() bool {
+			for _, vp := range vps[1:] {
+				if !seenBy(vp, src.cv.Self().Names(), "1") {
+					return false
+				}
+			}
+			return true
+		}, time.Millisecond*500, time.Millisecond*10)
+		assert.Equal(t, uint64(3), eagerPushes(src)-before)
+	})
+
+	t.Run("rate_limit", func(t *testing.T) {
+		_, vps, detach := setupCluster(t, false, WithEagerPushInterval(time.Millisecond*200))
+		defer detach()
+
+		src := vps[0]
+		before := eagerPushes(src)
+		for i := 0; i < 10; i++ {
+			set(t, src, strconv.FormatInt(int64(i), 10))
+		}
+		// changes within the interval are merged, so that there are 2 pushes at most.
+		assert.Eventually(t, func() bool {
+			for _, vp := range vps[1:] {
+				if !seenBy(vp, src.cv.Self().Names(), "9") {
+					return false
+				}
+			}
+			return true
+		}, time.Second, time.Millisecond*10)
+		pushes := eagerPushes(src) - before
+		assert.GreaterOrEqual(t, pushes, uint64(3))
+		assert.LessOrEqual(t, pushes, uint64(6))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		_, vps, detach := setupCluster(t, false, WithEagerPushInterval(0))
+		defer detach()
+
+		src := vps[0]
+		set(t, src, "1")
+		time.Sleep(time.Millisecond * 200)
+		for _, vp := range vps[1:] {
+			assert.False(t, seenBy(vp, src.cv.Self().Names(), "1"))
+		}
+		assert.Equal(t, uint64(0), eagerPushes(src))
+	})
+	t.Run("manual_sync", func(t *testing.T) {
+		_, vps, detach := setupCluster(t, true)
+		defer detach()
+
+		src := vps[0]
+		set(t, src, "1")
+		time.Sleep(time.Millisecond * 200)
+		for _, vp := range vps[1:] {
+			assert.False(t, seenBy(vp, src.cv.Self().Names(), "1"))
+		}
+		assert.Equal(t, uint64(0), eagerPushes(src))
+	})
+}
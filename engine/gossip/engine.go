@@ -28,7 +28,7 @@ type Transport interface {
 // engine options
 
 const (
-	defaultSuspectTimeout    = time.Minute * 5
+	defaultSuspectTimeout    = 0 // derived from gossip period.
 	defaultGossipPeriod      = time.Second
 	defaultMinimumRegionPeer = 1
 	defaultSWIMTagKey        = "_swim_tag"
@@ -53,8 +53,48 @@ func WithRegion(name string) sladder.EngineOption { return region(name) }
 type suspectTimeout time.Duration
 
 // WithSuspectTimeout creates option of suspection timeout.
+// It's the maximum suspicion window before a suspected node is claimed DEAD, which shrinks as peers confirm the suspection.
+// 0 means the window is derived from gossip period and cluster size.
 func WithSuspectTimeout(t time.Duration) sladder.EngineOption { return suspectTimeout(t) }
 
+type maxLocalHealth uint
+
+// WithMaxLocalHealth creates option to limit local health multiplier.
+// Failed probes raise local health multiplier and successful ones lower it. Probe timeouts are stretched by
+// (multiplier + 1) times, so that a slow node won't falsely suspect healthy peers. 0 disables the stretching.
+func WithMaxLocalHealth(n uint) sladder.EngineOption { return maxLocalHealth(n) }
+
+type suspicionConfirmations uint
+
+// WithSuspicionConfirmations creates option of expected peer confirmations of a suspection.
+// Suspicion window reaches its lower bound after the expected confirmations. 0 disables the shrinking.
+func WithSuspicionConfirmations(n uint) sladder.EngineOption { return suspicionConfirmations(n) }
+
+type retransmitMult uint
+
+// WithRetransmitMult creates option of SWIM tag update retransmission.
+// Recent SWIM tag updates are piggybacked on failure detector messages, each for (mult * log10(N + 1)) times,
+// where N is cluster size. 0 disables the piggybacking.
+func WithRetransmitMult(mult uint) sladder.EngineOption { return retransmitMult(mult) }
+
+type eagerPushInterval time.Duration
+
+// WithEagerPushInterval creates option of minimum interval between eager pushes.
+// Local changes to self are pushed to peers immediately after commit, instead of waiting for the next gossip period.
+// Pushes within the interval are merged into one. 0 disables eager push.
+func WithEagerPushInterval(t time.Duration) sladder.EngineOption { return eagerPushInterval(t) }
+
+type seedProvider struct{ SeedProvider }
+
+// WithSeedProvider creates option of seed provider, whose seeds are joined by Join().
+func WithSeedProvider(p SeedProvider) sladder.EngineOption { return seedProvider{p} }
+
+type incarnationStore struct{ IncarnationStore }
+
+// WithIncarnationStore creates option of incarnation store.
+// Incarnation is saved with versions reserved ahead, and restored on start, so that a restarted node starts above its former life.
+func WithIncarnationStore(s IncarnationStore) sladder.EngineOption { return incarnationStore{s} }
+
 type swimTagKey string
 
 // WithSWIMTagKey creates option of SWIM tag key.
@@ -77,7 +117,7 @@ func WithGossipPeriod(t time.Duration) sladder.EngineOption { return gossipPerio
 
 type manualSync struct{}
 
-// ManualSync disables auto sync. (for testing)
+// ManualSync disables auto sync, including eager push. (for testing)
 func ManualSync() sladder.EngineOption { return manualSync{} }
 
 type manualFailureDetect struct{}
@@ -97,6 +137,32 @@ type quitTimeout time.Duration
 // Specially, 0 means infinite timeout.
 func WithQuitTimeout(d time.Duration) sladder.EngineOption { return quitTimeout(d) }
 
+type syncMode SyncMode
+
+// WithSyncMode creates option of sync mode.
+func WithSyncMode(mode SyncMode) sladder.EngineOption { return syncMode(mode) }
+
+type maxSyncMessageSize int
+
+// WithMaxSyncMessageSize creates option to limit size of sync messages.
+// Cluster snapshots larger than the limitation are split into pages. Each sync round sends one of pages in rotation,
+// and responses are sent in multiple pages. 0 means no limitation.
+func WithMaxSyncMessageSize(n int) sladder.EngineOption { return maxSyncMessageSize(n) }
+
+type compressThreshold int
+
+// WithCompression creates option to compress gossip messages.
+// Message bodies of at least threshold bytes are compressed by DEFLATE. threshold < 1 means default threshold.
+// Compressed messages are always accepted regardless of this option, but older versions cannot decode them.
+// Enable it after all nodes are upgraded.
+func WithCompression(threshold int) sladder.EngineOption { return compressThreshold(threshold) }
+
+type keyring struct{ *Keyring }
+
+// WithKeyring creates option to seal gossip messages with keyring.
+// Incoming messages which fail to authenticate are dropped and counted in Metrics.Security.
+func WithKeyring(k *Keyring) sladder.EngineOption { return keyring{k} }
+
 // Engine provides methods to create gossip engine instance.
 type Engine struct{}
 
@@ -114,6 +180,18 @@ func New(transport Transport, options ...sladder.EngineOption) sladder.EngineIns
 			instance.region = string(v)
 		case suspectTimeout:
 			instance.SuspectTimeout = time.Duration(v)
+		case maxLocalHealth:
+			instance.maxLocalHealth = uint(v)
+		case suspicionConfirmations:
+			instance.suspicionConfirmations = uint(v)
+		case retransmitMult:
+			instance.retransmitMult = uint(v)
+		case eagerPushInterval:
+			instance.eagerPushInterval = time.Duration(v)
+		case seedProvider:
+			instance.seedProvider = v.SeedProvider
+		case incarnationStore:
+			instance.incarnationStore = v.IncarnationStore
 		case swimTagKey:
 			instance.swimTagKey = string(v)
 		case logger:
@@ -136,8 +214,27 @@ func New(transport Transport, options ...sladder.EngineOption) sladder.EngineIns
 			instance.disableSync = true
 		case quitTimeout:
 			instance.QuitTimeout = time.Duration(v)
+		case syncMode:
+			instance.syncMode = SyncMode(v)
+		case maxSyncMessageSize:
+			if v < 0 {
+				v = 0
+			}
+			instance.maxSyncMessageSize = int(v)
+		case compressThreshold:
+			instance.compression = true
+			if v > 0 {
+				instance.compressThreshold = int(v)
+			}
+		case keyring:
+			if v.Keyring != nil {
+				instance.transport = NewSealedTransport(transport, v.Keyring, &instance.Metrics.Security)
+			}
 		}
 	}
+	if sealed, isSealed := instance.transport.(*SealedTransport); isSealed {
+		sealed.SetLogger(instance.log)
+	}
 	return instance
 }
 
@@ -165,6 +262,16 @@ type EngineInstance struct {
 	QuitTimeout             time.Duration
 	region                  string
 	Fanout                  int32
+	syncMode                SyncMode
+	maxSyncMessageSize      int
+	compression             bool
+	compressThreshold       int
+	maxLocalHealth          uint
+	suspicionConfirmations  uint
+	retransmitMult          uint
+	eagerPushInterval       time.Duration
+	seedProvider            SeedProvider
+	incarnationStore        IncarnationStore
 
 	log       sladder.Logger
 	transport Transport
@@ -179,6 +286,11 @@ type EngineInstance struct {
 	counterSeed    uint64 // seed is to randomize message ID. (formula: message ID = seed + counter).
 	quitAfter      uint64
 
+	// incarnation fields.
+	generation      uint64      // boot generation.
+	incarnationLock sync.Mutex  // protects lastIncarnation.
+	lastIncarnation Incarnation // last saved or restored incarnation.
+
 	// txn fields.
 	innerTxnIDs sync.Map // map[uint32]struct{}
 
@@ -187,14 +299,20 @@ type EngineInstance struct {
 	leaveingNodeNameIndex map[string]int
 	reversedExistence     map[*sladder.Node]*reversedExistenceItem // trace self-existence from other nodes.
 	canQuit               bool
+	syncPageCursor        uint32
+	lastEagerPush         time.Time // last eager push.
+	eagerPushPending      bool      // an eager push is scheduled.
 
 	// failure detector fields.
-	inPing              map[*sladder.Node]*pingContext  // nodes in ping progress
-	roundTrips          map[*sladder.Node]time.Duration // round-trip time trace.
-	suspectionNodeIndex map[*sladder.Node]*suspection   // suspection indexed by node ptr.
-	suspectionQueue     suspectionQueue                 // heap order by suspection.notAfter.
-	pingTimeoutEvent    chan *sladder.Node              // ping timeout event.
-	pingReqTimeoutEvent chan *sladder.Node              // ping-req timeout event.
+	localHealth         uint                                  // local health multiplier.
+	inPing              map[*sladder.Node]*pingContext        // nodes in ping progress
+	probes              probeList                             // randomized round-robin probe list.
+	roundTrips          map[*sladder.Node]*roundTripEstimator // round-trip time trace.
+	suspectionNodeIndex map[*sladder.Node]*suspection         // suspection indexed by node ptr.
+	suspectionQueue     suspectionQueue                       // heap order by suspection.notAfter.
+	tagUpdates          map[*sladder.Node]*tagUpdate          // SWIM tag updates to piggyback.
+	pingTimeoutEvent    chan *sladder.Node                    // ping timeout event.
+	pingReqTimeoutEvent chan *sladder.Node                    // ping-req timeout event.
 
 	Metrics Metrics
 }
@@ -209,6 +327,12 @@ func newInstanceDefault(transport Transport) *EngineInstance {
 		Fanout:         1,
 		QuitTimeout:    defaultQuitTimeout,
 
+		compressThreshold:      defaultCompressThreshold,
+		maxLocalHealth:         defaultMaxLocalHealth,
+		suspicionConfirmations: defaultSuspicionConfirmations,
+		retransmitMult:         defaultRetransmitMult,
+		eagerPushInterval:      defaultEagerPushInterval,
+
 		withRegion: make(map[string]map[*sladder.Node]struct{}),
 
 		leaveingNodeNameIndex: make(map[string]int),
@@ -216,8 +340,9 @@ func newInstanceDefault(transport Transport) *EngineInstance {
 		canQuit:               false,
 
 		inPing:              make(map[*sladder.Node]*pingContext),
-		roundTrips:          make(map[*sladder.Node]time.Duration),
+		roundTrips:          make(map[*sladder.Node]*roundTripEstimator),
 		suspectionNodeIndex: make(map[*sladder.Node]*suspection),
+		tagUpdates:          make(map[*sladder.Node]*tagUpdate),
 
 		pingTimeoutEvent:    make(chan *sladder.Node, 20),
 		pingReqTimeoutEvent: make(chan *sladder.Node, 20),
@@ -298,10 +423,6 @@ func (e *EngineInstance) getPingProxiesCount() int32 {
 	return 5
 }
 
-func (e *EngineInstance) getMinPingReqTimeoutTimes() uint {
-	return 2
-}
-
 func (e *EngineInstance) tickGossipPeriodGo(proc func(time.Time)) {
 	period := e.getGossipPeriod()
 
@@ -356,6 +477,7 @@ func (e *EngineInstance) sendProto(names []string, body protoiface.MessageV1) {
 		e.log.Error("failed to marshal message body, got: " + err.Error())
 		return
 	}
+	e.compressMessage(&msg)
 	if raw, err = proto.Marshal(&msg); err != nil {
 		e.log.Error("failed to marshal gossip message, got: " + err.Error())
 		return
@@ -451,6 +573,10 @@ func (e *EngineInstance) pumpGossipMessage() {
 			e.log.Warn("invalid gossip message received, decoder got " + err.Error())
 			continue
 		}
+		if err := e.decompressMessage(&msg); err != nil {
+			e.log.Warn("cannot decompress gossip message, got " + err.Error())
+			continue
+		}
 
 		e.dispatchGossipMessage(from, &msg)
 	}
@@ -533,6 +659,10 @@ func (e *EngineInstance) Init(c *sladder.Cluster) (err error) {
 
 	e.cluster = c
 
+	if err = e.restoreIncarnation(); err != nil {
+		return err
+	}
+
 	// register SWIM tag.
 	if err = c.RegisterKey(e.swimTagKey, &SWIMTagValidator{}, true, 0); err != nil {
 		return err
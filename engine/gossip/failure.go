@@ -15,6 +15,11 @@ type suspection struct {
 	notAfter   time.Time
 	node       *sladder.Node
 	queueIndex int
+
+	version       uint32 // version of suspected SWIM tag.
+	start         time.Time
+	min, max      time.Duration              // bounds of suspicion window.
+	confirmations map[*sladder.Node]struct{} // peers confirming the suspection.
 }
 
 type suspectionQueue []*suspection
@@ -90,6 +95,8 @@ func (e *EngineInstance) _clearNodeFromFailureDetector(node *sladder.Node) {
 		delete(e.inPing, node)
 	}
 	delete(e.roundTrips, node)
+	delete(e.tagUpdates, node)
+	e.probes.remove(node)
 	if s, _ := e.suspectionNodeIndex[node]; s != nil {
 		heap.Remove(&e.suspectionQueue, s.queueIndex)
 		delete(e.suspectionNodeIndex, node)
@@ -125,8 +132,15 @@ func (e *EngineInstance) updateEngineRelatedFields(t *sladder.Transaction,
 	}
 
 	type stateUpdation struct {
-		node *sladder.Node
-		new  SWIMState
+		node    *sladder.Node
+		new     SWIMState
+		version uint32
+	}
+
+	type tagUpdation struct {
+		node  *sladder.Node
+		names []string
+		raw   string
 	}
 
 	var regionOp struct {
@@ -135,6 +149,8 @@ func (e *EngineInstance) updateEngineRelatedFields(t *sladder.Transaction,
 	}
 	selfRegionUpdated, newRegion := false, ""
 	var stateUpdates []*stateUpdation
+	var tagUpdates []*tagUpdation
+	var selfIncarnation *Incarnation
 
 	minc := &StateMetricIncrement{}
 	addStateMetricsByState := func(state SWIMState, n uint32) {
@@ -150,6 +166,18 @@ func (e *EngineInstance) updateEngineRelatedFields(t *sladder.Transaction,
 		}
 	}
 
+	// self SWIM tag may be updated by commit hooks, which are not in operation logs.
+	if self := e.cluster.Self(); t.KeyExists(self, e.swimTagKey) {
+		rtx, err := t.KV(self, e.swimTagKey)
+		if err != nil {
+			e.log.Errorf("engine cannot trace swim tag. (err = \"%v\")", err)
+			return false, err
+		}
+		if tag := rtx.(*SWIMTagTxn); tag.Updated() {
+			selfIncarnation = &Incarnation{Generation: tag.Generation(), Version: tag.Version()}
+		}
+	}
+
 	for node, idx := range nodes {
 		rtx, err := t.KV(node, e.swimTagKey)
 		if err != nil {
@@ -190,15 +218,25 @@ func (e *EngineInstance) updateEngineRelatedFields(t *sladder.Transaction,
 			}
 			if new, old := tag.State(), oldTag.State; new != old {
 				stateUpdates = append(stateUpdates, &stateUpdation{
-					node: node, new: new,
+					node: node, new: new, version: tag.Version(),
 				})
 
 				addStateMetricsByState(old, 0xFFFFFFFF) // state metrics incremental.
 				addStateMetricsByState(new, 1)          // state metrics incremental.
 			}
+			if tag.State() != oldTag.State || tag.Version() != oldTag.Version {
+				// state changes and refutations are disseminated by piggybacking.
+				tagUpdates = append(tagUpdates, &tagUpdation{
+					node: node, names: t.Names(node), raw: tag.After(),
+				})
+			}
 		}
 	}
 
+	if selfIncarnation != nil {
+		t.DeferOnCommit(func() { e.saveIncarnation(*selfIncarnation) })
+	}
+
 	if len(nodes) < 1 {
 		return true, nil
 	}
@@ -209,7 +247,8 @@ func (e *EngineInstance) updateEngineRelatedFields(t *sladder.Transaction,
 		if len(regionOp.insertions)+
 			len(regionOp.deletions)+
 			len(regionOp.updations)+
-			len(stateUpdates) <= 0 && !selfRegionUpdated {
+			len(stateUpdates)+
+			len(tagUpdates) <= 0 && !selfRegionUpdated {
 			return
 		}
 
@@ -221,6 +260,9 @@ func (e *EngineInstance) updateEngineRelatedFields(t *sladder.Transaction,
 		}
 		for _, insertion := range regionOp.insertions {
 			e.insertToRegion(insertion.region, insertion.node)
+			if insertion.node != e.cluster.Self() {
+				e.probes.insert(insertion.node)
+			}
 		}
 		for _, deletion := range regionOp.deletions {
 			e.removeFromRegion(deletion.region, deletion.node, -1)
@@ -231,14 +273,17 @@ func (e *EngineInstance) updateEngineRelatedFields(t *sladder.Transaction,
 			e.updateRegion(updation.old, updation.new, updation.node)
 		}
 		for _, updation := range stateUpdates {
-			e._traceSuspections(updation.node, updation.new)
+			e._traceSuspections(updation.node, updation.new, updation.version)
+		}
+		for _, updation := range tagUpdates {
+			e._queueTagUpdate(updation.node, updation.names, updation.raw)
 		}
 	})
 
 	return true, nil
 }
 
-func (e *EngineInstance) _traceSuspections(node *sladder.Node, new SWIMState) {
+func (e *EngineInstance) _traceSuspections(node *sladder.Node, new SWIMState, version uint32) {
 	// trace suspection states.
 	s, suspected := e.suspectionNodeIndex[node]
 	if new != SUSPECTED {
@@ -248,9 +293,14 @@ func (e *EngineInstance) _traceSuspections(node *sladder.Node, new SWIMState) {
 		}
 	} else if !suspected {
 		s = &suspection{
-			notAfter: time.Now().Add(e.getGossipPeriod() * 10),
-			node:     node,
-		}
+			node:          node,
+			version:       version,
+			start:         time.Now(),
+			confirmations: make(map[*sladder.Node]struct{}),
+		}
+		// a suspection without confirmations lasts for the whole window.
+		s.min, s.max = e._suspicionWindow()
+		s.notAfter = s.start.Add(s.timeout(e.suspicionConfirmations))
 		heap.Push(&e.suspectionQueue, s)
 		e.suspectionNodeIndex[node] = s
 	}
@@ -497,25 +547,15 @@ func (e *EngineInstance) DetectFailure() {
 		return
 	}
 
-	nodes := e.selectRandomNodes(e.getGossipFanout(), true)
-	if len(nodes) < 1 {
-		return
-	}
+	e.lock.Lock()
+	nodes := e.probes.pick(int(e.getGossipFanout()))
+	e.lock.Unlock()
 
 	for _, node := range nodes {
 		e.ping(node, nil)
 	}
 }
 
-func (e *EngineInstance) estimatedRoundTrip(node *sladder.Node) time.Duration {
-	return e.getGossipPeriod()
-	//rtt, estimated := e.roundTrips[node]
-	//if !estimated || rtt < 1 {
-	//	return e.getGossipPeriod()
-	//}
-	//return rtt
-}
-
 func (e *EngineInstance) processFailureDetectionProto(from []string, msg *pb.GossipMessage) {
 	switch msg.Type {
 	case pb.GossipMessage_Ack:
@@ -524,6 +564,7 @@ func (e *EngineInstance) processFailureDetectionProto(from []string, msg *pb.Gos
 			e.log.Error("invalid ack body, got " + err.Error())
 			break
 		}
+		e.applyTagUpdates(from, ack.Updates)
 		e.onPingAck(from, ack)
 
 	case pb.GossipMessage_Ping:
@@ -532,6 +573,7 @@ func (e *EngineInstance) processFailureDetectionProto(from []string, msg *pb.Gos
 			e.log.Error("invalid ping body, got " + err.Error())
 			break
 		}
+		e.applyTagUpdates(from, ping.Updates)
 		e.onPing(from, ping)
 
 	case pb.GossipMessage_PingReq:
@@ -540,6 +582,7 @@ func (e *EngineInstance) processFailureDetectionProto(from []string, msg *pb.Gos
 			e.log.Error("invalid ping-req body, got " + err.Error())
 			break
 		}
+		e.applyTagUpdates(from, pingReq.Updates)
 		e.onPingReq(from, pingReq)
 	}
 }
@@ -562,7 +605,8 @@ func (e *EngineInstance) ping(node *sladder.Node, proxyReq *proxyPingRequest) {
 	if pingCtx == nil { // not in progres.
 		id := e._generateMessageID()
 		defer e.sendProto(names, &pb.Ping{
-			Id: id,
+			Id:      id,
+			Updates: e._piggybackTagUpdates(minc),
 		})
 
 		pingCtx = &pingContext{
@@ -571,7 +615,7 @@ func (e *EngineInstance) ping(node *sladder.Node, proxyReq *proxyPingRequest) {
 		}
 
 		// after a ping timeout, a ping-req may be sent.
-		time.AfterFunc(e.estimatedRoundTrip(node)*2, func() {
+		time.AfterFunc(e._scaleByLocalHealth(e._roundTripTimeout(node)), func() {
 			e.pingTimeoutEvent <- node
 		})
 
@@ -616,9 +660,10 @@ func (e *EngineInstance) onPingAck(from []string, msg *pb.Ack) {
 	pingCtx.lock.Lock()
 	defer pingCtx.lock.Unlock()
 
-	// save estimated round-trip time.
-	rtt := time.Now().Sub(pingCtx.start)
-	e.roundTrips[node] = rtt
+	if len(msg.NamesProxyFor) < 1 {
+		// only direct acks measure round trip to node.
+		e._traceRoundTrip(node, time.Now().Sub(pingCtx.start))
+	}
 
 	if numOfProxied := len(pingCtx.proxyFor); numOfProxied > 0 {
 		for _, pingReq := range pingCtx.proxyFor {
@@ -626,6 +671,7 @@ func (e *EngineInstance) onPingAck(from []string, msg *pb.Ack) {
 			e.sendProto(pingReq.origin, &pb.Ack{
 				NamesProxyFor: pingReq.target,
 				Id:            pingReq.id,
+				Updates:       e._piggybackTagUpdates(minc),
 			})
 		}
 
@@ -633,6 +679,7 @@ func (e *EngineInstance) onPingAck(from []string, msg *pb.Ack) {
 	}
 	minc.Ping--
 	minc.Success++
+	e._adjustLocalHealth(-1, minc)
 
 	delete(e.inPing, node)
 }
@@ -644,7 +691,8 @@ func (e *EngineInstance) onPing(from []string, msg *pb.Ping) {
 
 	// ack.
 	e.sendProto(from, &pb.Ack{
-		Id: msg.Id,
+		Id:      msg.Id,
+		Updates: e.piggybackTagUpdates(),
 	})
 }
 
@@ -662,13 +710,14 @@ func (e *EngineInstance) processPingTimeout(node *sladder.Node) {
 	}
 
 	req := &pb.PingReq{
-		Id:   pingCtx.id,
-		Name: node.Names(),
+		Id:      pingCtx.id,
+		Name:    node.Names(),
+		Updates: e.piggybackTagUpdates(),
 	}
 	minc := &FailureDetectorMetricIncrement{}
 	defer e.Metrics.FailureDetector.ApplyIncrement(minc)
 
-	timeout := time.Duration(0)
+	var proxies []*sladder.Node
 	pingCtx.lock.Lock()
 	for _, proxy := range e.selectRandomNodes(e.getPingProxiesCount(), true) {
 		if proxy == node {
@@ -678,19 +727,22 @@ func (e *EngineInstance) processPingTimeout(node *sladder.Node) {
 		e.sendProto(proxy.Names(), req) // ping-req.
 		pingCtx.indirects++
 		minc.PingIndirect++
-
-		// find minimal proxier round-trip.
-		if rtt := e.estimatedRoundTrip(proxy); timeout < 1 || rtt < timeout {
-			timeout = rtt
-		}
+		proxies = append(proxies, proxy)
 	}
 	pingCtx.lock.Unlock()
 
-	if gossipPeriod := e.getGossipPeriod(); gossipPeriod > timeout {
-		timeout = gossipPeriod
+	// a ping-req takes round trip to the fastest proxier, plus a round trip from proxier to node.
+	timeout := time.Duration(0)
+	e.lock.RLock()
+	for _, proxy := range proxies {
+		if rtt := e._roundTripTimeout(proxy); timeout < 1 || rtt < timeout {
+			timeout = rtt
+		}
 	}
+	timeout = e._scaleByLocalHealth(timeout + e._roundTripTimeout(node))
+	e.lock.RUnlock()
 
-	time.AfterFunc(timeout*time.Duration(e.getMinPingReqTimeoutTimes()), func() {
+	time.AfterFunc(timeout, func() {
 		e.pingReqTimeoutEvent <- node
 	})
 }
@@ -731,6 +783,7 @@ func (e *EngineInstance) processPingReqTimeout(node *sladder.Node) {
 		delete(e.inPing, node)
 		minc.Ping--
 		minc.Failure++
+		e._adjustLocalHealth(1, minc)
 	}
 	e.lock.Unlock()
 
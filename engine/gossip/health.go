@@ -0,0 +1,106 @@
+package gossip
+
+import (
+	"container/heap"
+	"math"
+	"time"
+
+	"github.com/crossmesh/sladder"
+)
+
+const (
+	defaultMaxLocalHealth          = 8
+	defaultSuspicionConfirmations  = 3
+	defaultSuspicionMult           = 4
+	defaultSuspicionMaxTimeoutMult = 6
+)
+
+// LocalHealth returns local health multiplier. 0 means healthy.
+// A larger value means this node itself is likely slow, and probe timeouts are stretched by (LocalHealth() + 1) times.
+func (e *EngineInstance) LocalHealth() uint {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return e.localHealth
+}
+
+func (e *EngineInstance) _adjustLocalHealth(delta int, minc *FailureDetectorMetricIncrement) {
+	old := e.localHealth
+	health := int(old) + delta
+	if health < 0 {
+		health = 0
+	} else if max := int(e.maxLocalHealth); health > max {
+		health = max
+	}
+	e.localHealth = uint(health)
+	minc.LocalHealth += uint32(health - int(old))
+}
+
+func (e *EngineInstance) adjustLocalHealth(delta int) {
+	minc := &FailureDetectorMetricIncrement{}
+	e.lock.Lock()
+	e._adjustLocalHealth(delta, minc)
+	e.lock.Unlock()
+	e.Metrics.FailureDetector.ApplyIncrement(minc)
+}
+
+func (e *EngineInstance) _scaleByLocalHealth(d time.Duration) time.Duration {
+	return d * time.Duration(e.localHealth+1)
+}
+
+// _suspicionWindow returns bounds of suspicion window.
+// The lower bound grows logarithmically with cluster size, and the upper bound is SuspectTimeout if configured.
+func (e *EngineInstance) _suspicionWindow() (min, max time.Duration) {
+	n := 0
+	for _, nodes := range e.withRegion {
+		n += len(nodes)
+	}
+	scale := math.Max(1, math.Log10(float64(n)))
+	min = time.Duration(defaultSuspicionMult * scale * float64(e.getGossipPeriod()))
+	if max = e.SuspectTimeout; max < 1 {
+		max = min * defaultSuspicionMaxTimeoutMult
+	}
+	if max < min {
+		min = max
+	}
+	return
+}
+
+// timeout returns suspicion window according to number of confirmations.
+// The window shrinks logarithmically from max to min as confirmations arrive, and reaches min after expected ones.
+func (s *suspection) timeout(expected uint) time.Duration {
+	if expected < 1 || s.max <= s.min {
+		return s.max
+	}
+	frac := math.Log(float64(len(s.confirmations)+1)) / math.Log(float64(expected+1))
+	timeout := s.max - time.Duration(frac*float64(s.max-s.min))
+	if timeout < s.min {
+		timeout = s.min
+	}
+	return timeout
+}
+
+// confirmSuspections counts peer confirmations of suspections with specific tag versions.
+func (e *EngineInstance) confirmSuspections(from *sladder.Node, suspected map[*sladder.Node]uint32) {
+	minc := &FailureDetectorMetricIncrement{}
+	defer e.Metrics.FailureDetector.ApplyIncrement(minc)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for node, version := range suspected {
+		s, _ := e.suspectionNodeIndex[node]
+		if s == nil || s.version != version || node == from {
+			continue
+		}
+		if _, confirmed := s.confirmations[from]; confirmed {
+			continue
+		}
+		s.confirmations[from] = struct{}{}
+		minc.Confirmation++
+
+		if notAfter := s.start.Add(s.timeout(e.suspicionConfirmations)); notAfter.Before(s.notAfter) {
+			s.notAfter = notAfter
+			heap.Fix(&e.suspectionQueue, s.queueIndex)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	"github.com/crossmesh/sladder/engine/gossip/simnet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalHealth(t *testing.T) {
+	e := newInstanceDefault(nil)
+	assert.Equal(t, uint(0), e.LocalHealth())
+	assert.Equal(t, time.Second, e._scaleByLocalHealth(time.Second))
+
+	for i := 0; i < defaultMaxLocalHealth+2; i++ {
+		e.adjustLocalHealth(1)
+	}
+	assert.Equal(t, uint(defaultMaxLocalHealth), e.LocalHealth())
+	assert.Equal(t, uint32(defaultMaxLocalHealth), e.Metrics.FailureDetector.LocalHealth)
+	assert.Equal(t, time.Second*(defaultMaxLocalHealth+1), e._scaleByLocalHealth(time.Second))
+
+	for i := 0; i < defaultMaxLocalHealth+2; i++ {
+		e.adjustLocalHealth(-1)
+	}
+	assert.Equal(t, uint(0), e.LocalHealth())
+	assert.Equal(t, uint32(0), e.Metrics.FailureDetector.LocalHealth)
+
+	// disabled.
+	e = New(simnet.New().Transport("default"), WithMaxLocalHealth(0)).(*EngineInstance)
+	e.adjustLocalHealth(1)
+	assert.Equal(t, uint(0), e.LocalHealth())
+}
+
+func TestSuspicionWindow(t *testing.T) {
+	t.Run("bounds", func(t *testing.T) {
+		e := New(simnet.New().Transport("default"), WithGossipPeriod(time.Second)).(*EngineInstance)
+		min, max := e._suspicionWindow()
+		assert.Equal(t, defaultSuspicionMult*time.Second, min)
+		assert.Equal(t, defaultSuspicionMaxTimeoutMult*min, max)
+
+		// lower bound grows with cluster size.
+		nodes := make(map[*sladder.Node]struct{})
+		for i := 0; i < 100; i++ {
+			nodes[&sladder.Node{}] = struct{}{}
+		}
+		e.withRegion[""] = nodes
+		min, _ = e._suspicionWindow()
+		assert.Equal(t, 2*defaultSuspicionMult*time.Second, min)
+
+		// configured timeout is honoured.
+		e.SuspectTimeout = time.Minute
+		min, max = e._suspicionWindow()
+		assert.Equal(t, 2*defaultSuspicionMult*time.Second, min)
+		assert.Equal(t, time.Minute, max)
+		e.SuspectTimeout = time.Second
+		min, max = e._suspicionWindow()
+		assert.Equal(t, time.Second, min)
+		assert.Equal(t, time.Second, max)
+	})
+
+	t.Run("confirmations", func(t *testing.T) {
+		s := &suspection{
+			min: time.Second, max: time.Second * 10,
+			confirmations: make(map[*sladder.Node]struct{}),
+		}
+		assert.Equal(t, s.max, s.timeout(3))
+		last := s.max
+		for i := 0; i < 3; i++ {
+			s.confirmations[&sladder.Node{}] = struct{}{}
+			timeout := s.timeout(3)
+			assert.Less(t, int64(timeout), int64(last))
+			last = timeout
+		}
+		assert.Equal(t, s.min, last)
+		s.confirmations[&sladder.Node{}] = struct{}{}
+		assert.Equal(t, s.min, s.timeout(3))
+		assert.Equal(t, s.max, s.timeout(0))
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		e := New(simnet.New().Transport("default"), WithGossipPeriod(time.Second)).(*EngineInstance)
+		suspected, peer1, peer2 := &sladder.Node{}, &sladder.Node{}, &sladder.Node{}
+		e._traceSuspections(suspected, SUSPECTED, 2)
+		s := e.suspectionNodeIndex[suspected]
+		if !assert.NotNil(t, s) {
+			t.FailNow()
+		}
+		assert.Equal(t, s.start.Add(s.max), s.notAfter)
+
+		e.confirmSuspections(peer1, map[*sladder.Node]uint32{suspected: 1}) // stale version.
+		e.confirmSuspections(suspected, map[*sladder.Node]uint32{suspected: 2})
+		assert.Equal(t, 0, len(s.confirmations))
+
+		e.confirmSuspections(peer1, map[*sladder.Node]uint32{suspected: 2})
+		e.confirmSuspections(peer1, map[*sladder.Node]uint32{suspected: 2}) // duplicated.
+		assert.Equal(t, 1, len(s.confirmations))
+		assert.True(t, s.notAfter.Before(s.start.Add(s.max)))
+		e.confirmSuspections(peer2, map[*sladder.Node]uint32{suspected: 2})
+		assert.Equal(t, 2, len(s.confirmations))
+		assert.Equal(t, uint64(2), e.Metrics.FailureDetector.Confirmation)
+
+		e._traceSuspections(suspected, ALIVE, 3)
+		assert.Nil(t, e.suspectionNodeIndex[suspected])
+		assert.Equal(t, 0, e.suspectionQueue.Len())
+	})
+}
+
+func TestLifeguard(t *testing.T) {
+	period := time.Millisecond * 20
+
+	god, ctl, err := newHealthyClusterGod(t, "lg-tst", 1, 4, []sladder.EngineOption{
+		WithGossipPeriod(period), WithMaxLocalHealth(3), WithMinRegionPeer(4),
+	}, nil)
+	assert.NoError(t, err)
+	defer god.Detach(ctl)
+
+	vps := god.VPList()
+	isolated, others := vps[0], vps[1:]
+	ctl.NetworkOutJam(isolated.cv.Self().Names())
+	ctl.NetworkInJam(isolated.cv.Self().Names())
+
+	consistAt := syncLoop(t, vps, 500, func(round int) bool {
+		time.Sleep(period)
+		if isolated.engine.LocalHealth() < 3 {
+			return true
+		}
+		dead := true
+		for _, vp := range others {
+			vp.cv.Txn(func(tx *sladder.Transaction) bool {
+				rtx, err := tx.KV(tx.MostPossibleNode(isolated.cv.Self().Names()), vp.engine.swimTagKey)
+				if assert.NoError(t, err) && rtx.(*SWIMTagTxn).State() != DEAD {
+					dead = false
+				}
+				return false
+			})
+		}
+		return !dead
+	}, false, false, false, func(e *EngineInstance) {
+		e.ClusterSync()
+		e.DetectFailure()
+		e.ClearSuspections()
+	})
+	assert.Less(t, consistAt, 500, "isolated node isn't detected within 500 round.")
+
+	// the isolated node fails all probes.
+	assert.Equal(t, uint(3), isolated.engine.LocalHealth())
+	confirmations := uint64(0)
+	for _, vp := range others {
+		vp.engine.Metrics.FailureDetector.lock.Lock()
+		confirmations += vp.engine.Metrics.FailureDetector.Confirmation
+		vp.engine.Metrics.FailureDetector.lock.Unlock()
+	}
+	assert.Greater(t, confirmations, uint64(0))
+}
@@ -0,0 +1,90 @@
+package gossip
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/crossmesh/sladder/util"
+)
+
+// incarnationVersionReserve is number of versions reserved by each save of incarnation.
+// A saved version is a high-water mark, so that the store is not written for every version change.
+const incarnationVersionReserve = 64
+
+// Incarnation identifies a life of node.
+type Incarnation struct {
+	Generation uint64 `json:"g"` // boot generation.
+	Version    uint32 `json:"v"` // SWIM tag version.
+}
+
+// IncarnationStore persists incarnation of local node across restarts.
+type IncarnationStore interface {
+	// Load returns the last saved incarnation. Zero incarnation is returned if nothing saved.
+	Load() (Incarnation, error)
+	// Save saves incarnation.
+	Save(Incarnation) error
+}
+
+// FileIncarnationStore stores incarnation in file.
+type FileIncarnationStore string
+
+// Load reads incarnation from file. Missing file is treated as nothing saved.
+func (f FileIncarnationStore) Load() (inc Incarnation, err error) {
+	raw, err := ioutil.ReadFile(string(f))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Incarnation{}, nil
+		}
+		return Incarnation{}, err
+	}
+	if err = json.Unmarshal(raw, &inc); err != nil {
+		return Incarnation{}, err
+	}
+	return inc, nil
+}
+
+// Save writes incarnation to file atomically.
+func (f FileIncarnationStore) Save(inc Incarnation) error {
+	raw, err := json.Marshal(&inc)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(string(f), raw, 0600)
+}
+
+// restoreIncarnation loads the last incarnation and generates a new boot generation above it.
+func (e *EngineInstance) restoreIncarnation() (err error) {
+	if e.incarnationStore != nil {
+		if e.lastIncarnation, err = e.incarnationStore.Load(); err != nil {
+			return err
+		}
+	}
+	e.generation = uint64(time.Now().UnixNano())
+	if e.generation <= e.lastIncarnation.Generation {
+		e.generation = e.lastIncarnation.Generation + 1
+	}
+	return nil
+}
+
+// saveIncarnation saves incarnation once the version crosses the saved high-water mark.
+// A restarted node starts above the mark, which is never below versions used by the former life.
+func (e *EngineInstance) saveIncarnation(inc Incarnation) {
+	if e.incarnationStore == nil {
+		return
+	}
+
+	e.incarnationLock.Lock()
+	defer e.incarnationLock.Unlock()
+
+	if inc.Generation == e.lastIncarnation.Generation && inc.Version <= e.lastIncarnation.Version {
+		return
+	}
+	inc.Version += incarnationVersionReserve
+	if err := e.incarnationStore.Save(inc); err != nil {
+		e.log.Errorf("failed to save incarnation. (err = \"%v\")", err)
+		return
+	}
+	e.lastIncarnation = inc
+}
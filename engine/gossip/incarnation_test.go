@@ -0,0 +1,159 @@
+package gossip
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crossmesh/sladder"
+	"github.com/crossmesh/sladder/engine/gossip/simnet"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryIncarnationStore struct {
+	inc   Incarnation
+	saves int
+	err   error
+}
+
+func (s *memoryIncarnationStore) Load() (Incarnation, error) { return s.inc, s.err }
+func (s *memoryIncarnationStore) Save(inc Incarnation) error {
+	s.inc = inc
+	s.saves++
+	return nil
+}
+
+func TestFileIncarnationStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sladder-incarnation")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	s := FileIncarnationStore(filepath.Join(dir, "incarnation"))
+	inc, err := s.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, Incarnation{}, inc)
+
+	assert.NoError(t, s.Save(Incarnation{Generation: 123, Version: 7}))
+	assert.NoError(t, s.Save(Incarnation{Generation: 123, Version: 8}))
+	inc, err = s.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, Incarnation{Generation: 123, Version: 8}, inc)
+
+	// no temporary file left.
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(files))
+
+	assert.NoError(t, ioutil.WriteFile(string(s), []byte("corrupted"), 0600))
+	_, err = s.Load()
+	assert.Error(t, err)
+}
+
+func TestSWIMTagGeneration(t *testing.T) {
+	v := &SWIMTagValidator{engine: newInstanceDefault(nil)}
+	v.engine.log = sladder.DefaultLogger
+
+	sync := func(local, remote *SWIMTag) (bool, string) {
+		e := &sladder.KeyValue{Key: "tst", Value: local.Encode()}
+		ok, err := v.Sync(e, &sladder.KeyValue{Key: "tst", Value: remote.Encode()})
+		assert.NoError(t, err)
+		return ok, e.Value
+	}
+
+	// restarted node with lower version.
+	old, restarted := &SWIMTag{Version: 10, State: DEAD, Generation: 1}, &SWIMTag{Version: 1, Generation: 2}
+	ok, value := sync(old, restarted)
+	assert.True(t, ok)
+	assert.Equal(t, restarted.Encode(), value)
+	assert.True(t, restarted.Succeeds(old))
+
+	// zombie of former life.
+	ok, value = sync(restarted, old)
+	assert.False(t, ok)
+	assert.Equal(t, restarted.Encode(), value)
+	assert.False(t, old.Succeeds(restarted))
+
+	// tags without generation are compared by version.
+	legacy := &SWIMTag{Version: 11, State: DEAD}
+	ok, _ = sync(restarted, legacy)
+	assert.True(t, ok)
+	assert.True(t, legacy.Succeeds(restarted))
+	assert.False(t, restarted.Succeeds(legacy))
+}
+
+func TestIncarnationRestore(t *testing.T) {
+	newCluster := func(store IncarnationStore) (*EngineInstance, *sladder.Cluster, error) {
+		e := New(simnet.New().Transport("inc-tst"), WithIncarnationStore(store),
+			ManualSync(), ManualFailureDetect(), ManualClearSuspections()).(*EngineInstance)
+		cv, _, err := sladder.NewClusterWithNameResolver(e, &sladder.TestNamesInKeyNameResolver{Key: "idkey"})
+		return e, cv, err
+	}
+	selfTag := func(e *EngineInstance, cv *sladder.Cluster) (tag *SWIMTag) {
+		cv.Txn(func(tx *sladder.Transaction) bool {
+			rtx, err := tx.KV(cv.Self(), e.swimTagKey)
+			if assert.NoError(t, err) {
+				tag = &rtx.(*SWIMTagTxn).tag
+			}
+			return false
+		})
+		return
+	}
+
+	store := &memoryIncarnationStore{inc: Incarnation{Generation: 1, Version: 10}}
+	e, cv, err := newCluster(store)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	tag := selfTag(e, cv)
+	assert.Equal(t, uint32(11), tag.Version)
+	assert.Greater(t, tag.Generation, uint64(1))
+	assert.Equal(t, Incarnation{Generation: tag.Generation, Version: 11 + incarnationVersionReserve}, store.inc)
+
+	// version changes below the high-water mark are not saved.
+	saves := store.saves
+	assert.NoError(t, cv.RegisterKey("idkey", &sladder.TestNamesInKeyIDValidator{}, false, 0))
+	assert.NoError(t, cv.Txn(func(tx *sladder.Transaction) bool {
+		rtx, err := tx.KV(cv.Self(), "idkey")
+		if !assert.NoError(t, err) {
+			return false
+		}
+		rtx.(*sladder.TestNamesInKeyTxn).AddName("inc-tst")
+		return true
+	}))
+	tag = selfTag(e, cv)
+	assert.Equal(t, uint32(12), tag.Version)
+	assert.Equal(t, saves, store.saves)
+
+	// the mark is raised once crossed.
+	assert.NoError(t, cv.Txn(func(tx *sladder.Transaction) bool {
+		rtx, err := tx.KV(cv.Self(), e.swimTagKey)
+		if !assert.NoError(t, err) {
+			return false
+		}
+		rtx.(*SWIMTagTxn).BumpVersionAbove(11 + incarnationVersionReserve)
+		return true
+	}))
+	tag = selfTag(e, cv)
+	assert.Equal(t, uint32(12+incarnationVersionReserve), tag.Version)
+	assert.Equal(t, Incarnation{Generation: tag.Generation, Version: 12 + 2*incarnationVersionReserve}, store.inc)
+	assert.Equal(t, saves+1, store.saves)
+
+	// restart.
+	saves = store.saves
+	e2, cv2, err := newCluster(store)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	restarted := selfTag(e2, cv2)
+	assert.Equal(t, uint32(13+2*incarnationVersionReserve), restarted.Version)
+	assert.True(t, restarted.Succeeds(tag))
+	assert.Equal(t, saves+1, store.saves)
+
+	// failed to load.
+	_, _, err = newCluster(&memoryIncarnationStore{err: errors.New("broken")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,181 @@
+package gossip
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	pb "github.com/crossmesh/sladder/engine/gossip/pb"
+	"github.com/crossmesh/sladder/proto"
+)
+
+var (
+	ErrNoSeed = errors.New("no seed to join")
+)
+
+const (
+	joinMinBackoff   = time.Millisecond * 100
+	joinMaxBackoff   = time.Second * 10
+	joinPollInterval = time.Millisecond * 10
+)
+
+// SeedProvider provides seed nodes to join.
+type SeedProvider interface {
+	// Seeds returns names of seed nodes.
+	Seeds() ([][]string, error)
+}
+
+// StaticSeeds is fixed seed list.
+type StaticSeeds [][]string
+
+// Seeds returns the seed list.
+func (s StaticSeeds) Seeds() ([][]string, error) { return s, nil }
+
+// FileSeeds reads seeds from file, in which each line contains names of a seed separated by spaces.
+// Empty lines and lines starting with '#' are ignored. File is read every time seeds are wanted.
+type FileSeeds string
+
+// Seeds returns seeds in the file.
+func (f FileSeeds) Seeds() (seeds [][]string, err error) {
+	file, err := os.Open(string(f))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		seeds = append(seeds, strings.Fields(line))
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return seeds, nil
+}
+
+func (e *EngineInstance) joinSeeds(seeds [][]string) ([][]string, error) {
+	if e.seedProvider != nil {
+		provided, err := e.seedProvider.Seeds()
+		if err != nil {
+			return nil, err
+		}
+		seeds = append(append([][]string{}, seeds...), provided...)
+	}
+
+	// filter out myself.
+	selfNames := make(map[string]struct{})
+	for _, name := range e.cluster.Self().Names() {
+		selfNames[name] = struct{}{}
+	}
+	filtered := make([][]string, 0, len(seeds))
+	for _, seed := range seeds {
+		if len(seed) < 1 {
+			continue
+		}
+		isSelf := false
+		for _, name := range seed {
+			if _, isSelf = selfNames[name]; isSelf {
+				break
+			}
+		}
+		if !isSelf {
+			filtered = append(filtered, seed)
+		}
+	}
+
+	return filtered, nil
+}
+
+// acknowledgedSince returns true if any member has seen myself in syncs since the message with raw ID epoch.
+func (e *EngineInstance) acknowledgedSince(epoch uint64) bool {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	for _, trace := range e.reversedExistence {
+		if trace.exist && trace.epoch >= epoch {
+			return true
+		}
+	}
+	return false
+}
+
+// Join joins an existing cluster by push-pulling with seeds, including ones from seed provider.
+// Push-pulls are retried with exponential backoff until myself is acknowledged by at least one member,
+// or the context is done.
+func (e *EngineInstance) Join(ctx context.Context, seeds ...[]string) error {
+	var (
+		epoch   uint64 // raw ID of the first push-pull.
+		started bool
+	)
+
+	for backoff := joinMinBackoff; ; {
+		targets, err := e.joinSeeds(seeds)
+		if err != nil {
+			return err
+		}
+		if len(targets) < 1 {
+			return ErrNoSeed
+		}
+
+		var nodes []*proto.Node
+		e.cluster.Txn(func(t *sladder.Transaction) bool {
+			snap, names := e.newSyncClusterSnapshotWithNames(t)
+			if e.maxSyncMessageSize > 0 {
+				sortSnapshotNodes(snap.Nodes, names)
+			}
+			nodes = snap.Nodes
+			return false
+		}, sladder.MembershipModification())
+
+		minc := &SyncMetricIncrement{}
+		for _, seed := range targets {
+			e.lock.Lock()
+			id := e._generateMessageID()
+			e.lock.Unlock()
+			if !started {
+				epoch, started = id-e.counterSeed, true
+			}
+
+			// the rest pages are pushed eagerly, since their IDs are generated by myself and cannot be traced by the seed.
+			e.sendSyncPages(seed, id, pb.Sync_PushPull, pb.Sync_Eager, nodes, nil)
+			minc.PushPull++
+		}
+		e.Metrics.Sync.ApplyIncrement(minc)
+
+		// wait for acknowledgement.
+		if acked, err := e.waitAcknowledgement(ctx, epoch, backoff); err != nil || acked {
+			return err
+		}
+
+		if backoff *= 2; backoff > joinMaxBackoff {
+			backoff = joinMaxBackoff
+		}
+	}
+}
+
+func (e *EngineInstance) waitAcknowledgement(ctx context.Context, epoch uint64, timeout time.Duration) (bool, error) {
+	deadline, ticker := time.NewTimer(timeout), time.NewTicker(joinPollInterval)
+	defer deadline.Stop()
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-ticker.C:
+			if e.acknowledgedSince(epoch) {
+				return true, nil
+			}
+		case <-deadline.C:
+			return e.acknowledgedSince(epoch), nil
+		}
+	}
+}
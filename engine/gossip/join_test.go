@@ -0,0 +1,129 @@
+package gossip
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeedProvider(t *testing.T) {
+	seeds, err := StaticSeeds{{"n1"}, {"n2", "n3"}}.Seeds()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"n1"}, {"n2", "n3"}}, seeds)
+
+	dir, err := ioutil.TempDir("", "sladder-seeds")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "seeds")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("# seeds\nn1\n\n  n2 n3  \n#n4\n"), 0600))
+
+	seeds, err = FileSeeds(path).Seeds()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"n1"}, {"n2", "n3"}}, seeds)
+
+	_, err = FileSeeds(filepath.Join(dir, "missing")).Seeds()
+	assert.Error(t, err)
+}
+
+func TestJoin(t *testing.T) {
+	god, ctl, err := newClusterGod("join-tst", 1, 4, []sladder.EngineOption{
+		WithGossipPeriod(time.Second),
+		WithSeedProvider(StaticSeeds{{"join-tst-0-0"}}),
+	}, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer god.Detach(ctl)
+
+	seed := god.indexByName["join-tst-0-0"]
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	t.Run("no_seed", func(t *testing.T) {
+		assert.Equal(t, ErrNoSeed, seed.engine.Join(ctx))
+		assert.Equal(t, ErrNoSeed, seed.engine.Join(ctx, seed.cv.Self().Names()))
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, time.Millisecond*300)
+		defer cancel()
+		e := New(ctl.Transport("join-tst-x"), ManualSync(), ManualFailureDetect(), ManualClearSuspections()).(*EngineInstance)
+		_, _, err := sladder.NewClusterWithNameResolver(e, &sladder.TestNamesInKeyNameResolver{Key: "idkey"})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, context.DeadlineExceeded, e.Join(ctx, []string{"join-tst-nowhere"}))
+		ctl.RemoveTransportTarget("join-tst-x")
+	})
+
+	t.Run("join", func(t *testing.T) {
+		for _, vp := range god.VPList() {
+			if vp == seed {
+				continue
+			}
+			assert.NoError(t, vp.engine.Join(ctx))
+		}
+
+		// the seed knows all.
+		names := map[string]struct{}{}
+		seed.cv.RangeNodes(func(n *sladder.Node) bool {
+			for _, name := range n.Names() {
+				names[name] = struct{}{}
+			}
+			return true
+		}, false, false)
+		for name := range god.indexByName {
+			assert.Contains(t, names, name)
+		}
+
+		// cluster converges without further joins.
+		vps := god.VPList()
+		consistAt := syncLoop(t, vps, 100, func(round int) bool {
+			return !god.AllViewpointConsist(true, true)
+		}, false, false, false, nil)
+		assert.Less(t, consistAt, 100, "cluster cannot be consist within 100 round after join.")
+	})
+}
+
+func TestJoinPaginated(t *testing.T) {
+	god, ctl, err := newClusterGod("join-page-tst", 1, 3, []sladder.EngineOption{
+		WithGossipPeriod(time.Second),
+		WithMaxSyncMessageSize(syncMessageOverhead + 1), // a node per page.
+	}, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer god.Detach(ctl)
+
+	// pages are sorted by names. the seed sorts last, so that it is in one of the rest pages.
+	joiner, middle, seed := god.indexByName["join-page-tst-0-0"], god.indexByName["join-page-tst-1-0"], god.indexByName["join-page-tst-2-0"]
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	// the joiner learns the seed from another member.
+	assert.NoError(t, middle.engine.Join(ctx, seed.cv.Self().Names()))
+	assert.NoError(t, joiner.engine.Join(ctx, middle.cv.Self().Names()))
+	if !assert.NotNil(t, joiner.cv.GetNode("join-page-tst-2-0")) {
+		t.FailNow()
+	}
+
+	assert.NoError(t, joiner.engine.Join(ctx, seed.cv.Self().Names()))
+	assert.Eventually(t, func() bool {
+		return seed.cv.GetNode("join-page-tst-0-0") != nil
+	}, time.Second, time.Millisecond*10)
+	time.Sleep(time.Millisecond * 100) // wait for the rest pages.
+
+	// the rest pages are not traced as self existence.
+	seed.engine.lock.RLock()
+	trace := seed.engine.reversedExistence[seed.cv.GetNode("join-page-tst-0-0")]
+	seed.engine.lock.RUnlock()
+	assert.Nil(t, trace)
+}
@@ -3,6 +3,7 @@ package gossip
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/crossmesh/sladder"
 	"github.com/stretchr/testify/assert"
@@ -142,3 +143,44 @@ func TestKVWrapper(t *testing.T) {
 		}
 	})
 }
+
+func TestLocalEntry(t *testing.T) {
+	god, ctl, err := newHealthyClusterGod(t, "local-tst", 1, 3, nil, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer god.Detach(ctl)
+
+	vps := god.VPList()
+	for _, vp := range vps {
+		assert.NoError(t, vp.cv.RegisterKey("local", sladder.StringValidator{}, false, sladder.LocalEntry))
+		assert.NoError(t, vp.cv.RegisterKey("shared", sladder.StringValidator{}, false, 0))
+	}
+	src := vps[0]
+	assert.NoError(t, src.cv.Keys("local", "shared").Nodes(src.cv.Self()).Set("1").AsError())
+
+	// local entry is excluded from entry list.
+	assert.NoError(t, src.cv.Txn(func(tx *sladder.Transaction) bool {
+		rtx, err := tx.KV(src.cv.Self(), src.engine.swimTagKey)
+		if assert.NoError(t, err) {
+			assert.NotContains(t, rtx.(*SWIMTagTxn).EntryList(false), "local")
+			assert.Contains(t, rtx.(*SWIMTagTxn).EntryList(false), "shared")
+		}
+		return false
+	}))
+
+	for i := 0; i < 10; i++ {
+		for _, vp := range vps {
+			vp.engine.ClusterSync()
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	for _, vp := range vps[1:] {
+		r := vp.cv.Keys("local", "shared").Nodes(src.cv.Self().Names()[0]).Get()
+		assert.NoError(t, r.AsError())
+		for node, values := range r.Values {
+			assert.Equal(t, map[string]string{"shared": "1"}, values, "node %v", node.PrintableName())
+		}
+		assert.Equal(t, 1, len(r.Values))
+	}
+}
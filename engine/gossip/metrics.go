@@ -55,6 +55,9 @@ func (m *SyncMetrics) ApplyIncrement(inc *SyncMetricIncrement) {
 	m.IncomingPush += inc.IncomingPush
 	m.PushPull += inc.PushPull
 	m.Push += inc.Push
+	m.IncomingDigest += inc.IncomingDigest
+	m.Digest += inc.Digest
+	m.EagerPush += inc.EagerPush
 }
 
 // SyncMetricIncrement contains synchronization metric body.
@@ -64,6 +67,11 @@ type SyncMetricIncrement struct {
 
 	IncomingPush uint64 // incoming push requests.
 	Push         uint64 // send push requests.
+
+	IncomingDigest uint64 // incoming digest requests.
+	Digest         uint64 // sent digest requests.
+
+	EagerPush uint64 // sent eager pushes of local changes.
 }
 
 // FailureDetectorMetrics collects failure detector metrics.
@@ -89,6 +97,10 @@ func (m *FailureDetectorMetrics) ApplyIncrement(inc *FailureDetectorMetricIncrem
 	m.ProxyPing += inc.ProxyPing
 	m.ProxySuccess += inc.ProxySuccess
 	m.ProxyFailure += inc.ProxyFailure
+	m.LocalHealth += inc.LocalHealth
+	m.Confirmation += inc.Confirmation
+	m.Piggybacked += inc.Piggybacked
+	m.IncomingPiggybacked += inc.IncomingPiggybacked
 }
 
 // FailureDetectorMetricIncrement contains metrics of failure detector.
@@ -101,6 +113,82 @@ type FailureDetectorMetricIncrement struct {
 	ProxyPing    uint32 // proxy ping requests in progress.
 	ProxySuccess uint64 // successful proxy ping.
 	ProxyFailure uint64 // failed proxy ping.
+
+	LocalHealth  uint32 // local health multiplier.
+	Confirmation uint64 // peer confirmations of suspections.
+
+	Piggybacked         uint64 // SWIM tag updates piggybacked on outgoing messages.
+	IncomingPiggybacked uint64 // SWIM tag updates piggybacked on incoming messages.
+}
+
+// SecurityMetrics collects metrics of message sealing.
+type SecurityMetrics struct {
+	lock sync.Mutex
+
+	SecurityMetricIncrement
+}
+
+// ApplyIncrement applys SecurityMetricIncrement.
+func (m *SecurityMetrics) ApplyIncrement(inc *SecurityMetricIncrement) {
+	if inc == nil {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.Sealed += inc.Sealed
+	m.SealFailure += inc.SealFailure
+	m.Opened += inc.Opened
+	m.Malformed += inc.Malformed
+	m.Unauthenticated += inc.Unauthenticated
+}
+
+// SecurityMetricIncrement contains metrics of message sealing.
+type SecurityMetricIncrement struct {
+	Sealed      uint64 // sealed outgoing messages.
+	SealFailure uint64 // dropped outgoing messages which fail to seal.
+	Opened      uint64 // authenticated incoming messages.
+
+	Malformed       uint64 // dropped incoming messages not in form of sealed message.
+	Unauthenticated uint64 // dropped incoming messages which fail to authenticate.
+}
+
+// CompressionMetrics collects metrics of message compression.
+type CompressionMetrics struct {
+	lock sync.Mutex
+
+	CompressionMetricIncrement
+}
+
+// ApplyIncrement applys CompressionMetricIncrement.
+func (m *CompressionMetrics) ApplyIncrement(inc *CompressionMetricIncrement) {
+	if inc == nil {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.Compressed += inc.Compressed
+	m.UncompressedBytes += inc.UncompressedBytes
+	m.CompressedBytes += inc.CompressedBytes
+	m.Decompressed += inc.Decompressed
+	m.IncomingCompressedBytes += inc.IncomingCompressedBytes
+	m.IncomingUncompressedBytes += inc.IncomingUncompressedBytes
+	m.DecompressFailure += inc.DecompressFailure
+}
+
+// CompressionMetricIncrement contains metrics of message compression.
+type CompressionMetricIncrement struct {
+	Compressed        uint64 // compressed outgoing messages.
+	UncompressedBytes uint64 // body bytes of compressed outgoing messages before compression.
+	CompressedBytes   uint64 // body bytes of compressed outgoing messages after compression.
+
+	Decompressed              uint64 // decompressed incoming messages.
+	IncomingCompressedBytes   uint64 // body bytes of decompressed incoming messages before decompression.
+	IncomingUncompressedBytes uint64 // body bytes of decompressed incoming messages after decompression.
+	DecompressFailure         uint64 // dropped incoming messages which fail to decompress.
 }
 
 // Metrics collects gossip engine statistics.
@@ -113,6 +201,8 @@ type Metrics struct {
 	Sync            SyncMetrics
 	State           StateMetrics
 	FailureDetector FailureDetectorMetrics
+	Security        SecurityMetrics
+	Compression     CompressionMetrics
 }
 
 // PublishGossipPeriod publishs gossip period to metric.
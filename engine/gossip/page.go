@@ -0,0 +1,116 @@
+package gossip
+
+import (
+	"sort"
+
+	pb "github.com/crossmesh/sladder/engine/gossip/pb"
+	"github.com/crossmesh/sladder/proto"
+	"google.golang.org/protobuf/encoding/protowire"
+	gproto "google.golang.org/protobuf/proto"
+)
+
+const (
+	// syncMessageOverhead is estimated size of message fields other than nodes and digests,
+	// including the envelope and sealing overhead.
+	syncMessageOverhead = 128
+)
+
+// paginate splits n items into pages so that total encoded size of each page doesn't exceed limit.
+// An item larger than limit occupies a page alone.
+// Each page has reserved bytes for other fields, and the first page has extra firstReserved bytes.
+func paginate(n int, sizeOf func(int) int, limit, firstReserved, reserved int) (bounds [][2]int) {
+	if n < 1 {
+		return nil
+	}
+	budget := limit - syncMessageOverhead
+	if limit < 1 || budget < 1 {
+		return [][2]int{{0, n}}
+	}
+
+	start, used := 0, firstReserved+reserved
+	for idx := 0; idx < n; idx++ {
+		size := sizeOf(idx)
+		if idx > start && used+size > budget {
+			bounds = append(bounds, [2]int{start, idx})
+			start, used = idx, reserved
+		}
+		used += size
+	}
+	return append(bounds, [2]int{start, n})
+}
+
+func encodedSizeOfField(msg gproto.Message) int {
+	return 1 + protowire.SizeBytes(gproto.Size(msg)) // tag + length-delimited body.
+}
+
+func encodedSizeOfDigests(digests []*pb.NodeDigest) (size int) {
+	for _, digest := range digests {
+		size += encodedSizeOfField(digest)
+	}
+	return
+}
+
+// paginateNodes splits nodes into pages within limit bytes. limit < 1 means no limitation.
+func paginateNodes(nodes []*proto.Node, limit, firstReserved int) (pages [][]*proto.Node) {
+	for _, bound := range paginate(len(nodes), func(i int) int {
+		return encodedSizeOfField(nodes[i])
+	}, limit, firstReserved, 0) {
+		pages = append(pages, nodes[bound[0]:bound[1]])
+	}
+	return
+}
+
+// paginateDigests splits digests into pages within limit bytes. limit < 1 means no limitation.
+func paginateDigests(digests []*pb.NodeDigest, limit, reserved int) (pages [][]*pb.NodeDigest) {
+	for _, bound := range paginate(len(digests), func(i int) int {
+		return encodedSizeOfField(digests[i])
+	}, limit, 0, reserved) {
+		pages = append(pages, digests[bound[0]:bound[1]])
+	}
+	return
+}
+
+// sortSnapshotNodes sorts snapshot nodes by names, so that pages are stable across rounds.
+func sortSnapshotNodes(nodes []*proto.Node, names [][]string) {
+	key := func(i int) string {
+		if len(names[i]) < 1 {
+			return ""
+		}
+		return names[i][0]
+	}
+	sort.Sort(&snapshotNodeSorter{nodes: nodes, names: names, key: key})
+}
+
+type snapshotNodeSorter struct {
+	nodes []*proto.Node
+	names [][]string
+	key   func(int) string
+}
+
+func (s *snapshotNodeSorter) Len() int           { return len(s.nodes) }
+func (s *snapshotNodeSorter) Less(i, j int) bool { return s.key(i) < s.key(j) }
+func (s *snapshotNodeSorter) Swap(i, j int) {
+	s.nodes[i], s.nodes[j] = s.nodes[j], s.nodes[i]
+	s.names[i], s.names[j] = s.names[j], s.names[i]
+}
+
+// pinSnapshotNode moves node with any of names to the front.
+func pinSnapshotNode(nodes []*proto.Node, names [][]string, pin []string) {
+	if len(pin) < 1 {
+		return
+	}
+	for idx := range nodes {
+		for _, name := range names[idx] {
+			for _, p := range pin {
+				if name != p {
+					continue
+				}
+				node, nodeNames := nodes[idx], names[idx]
+				copy(nodes[1:idx+1], nodes[:idx])
+				copy(names[1:idx+1], names[:idx])
+				nodes[0], names[0] = node, nodeNames
+				return
+			}
+		}
+	}
+}
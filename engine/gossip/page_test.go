@@ -0,0 +1,116 @@
+package gossip
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/crossmesh/sladder"
+	pb "github.com/crossmesh/sladder/engine/gossip/pb"
+	"github.com/crossmesh/sladder/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	gproto "google.golang.org/protobuf/proto"
+)
+
+type syncRecordTransport struct {
+	Transport
+
+	lock    sync.Mutex
+	maxSize int
+	pages   uint32
+}
+
+func (t *syncRecordTransport) Send(names []string, buf []byte) {
+	var (
+		msg  pb.GossipMessage
+		sync pb.Sync
+	)
+	if err := gproto.Unmarshal(buf, &msg); err == nil && msg.Type == pb.GossipMessage_Sync {
+		if err = ptypes.UnmarshalAny(msg.Body, &sync); err == nil {
+			t.lock.Lock()
+			if len(buf) > t.maxSize {
+				t.maxSize = len(buf)
+			}
+			if sync.Pages > t.pages {
+				t.pages = sync.Pages
+			}
+			t.lock.Unlock()
+		}
+	}
+	t.Transport.Send(names, buf)
+}
+
+func TestPaginate(t *testing.T) {
+	sizeOf := func(i int) int { return 100 }
+	assert.Nil(t, paginate(0, sizeOf, 0, 0, 0))
+	assert.Equal(t, [][2]int{{0, 10}}, paginate(10, sizeOf, 0, 0, 0))
+	assert.Equal(t, [][2]int{{0, 10}}, paginate(10, sizeOf, syncMessageOverhead, 0, 0))
+	assert.Equal(t, [][2]int{{0, 3}, {3, 6}, {6, 9}, {9, 10}}, paginate(10, sizeOf, syncMessageOverhead+300, 0, 0))
+	assert.Equal(t, [][2]int{{0, 1}, {1, 4}, {4, 7}, {7, 10}}, paginate(10, sizeOf, syncMessageOverhead+300, 200, 0))
+	assert.Equal(t, [][2]int{{0, 2}, {2, 4}, {4, 6}, {6, 8}, {8, 10}}, paginate(10, sizeOf, syncMessageOverhead+300, 0, 100))
+	// oversize item.
+	assert.Equal(t, [][2]int{{0, 1}, {1, 2}}, paginate(2, func(i int) int { return 1000 }, syncMessageOverhead+300, 0, 0))
+
+	nodes := []*proto.Node{{}, {}, {}, {}}
+	names := [][]string{{"c"}, {"a"}, nil, {"b", "d"}}
+	expected := []*proto.Node{nodes[2], nodes[1], nodes[3], nodes[0]}
+	sortSnapshotNodes(nodes, names)
+	assert.Equal(t, expected, nodes)
+	assert.Equal(t, [][]string{nil, {"a"}, {"b", "d"}, {"c"}}, names)
+	pinSnapshotNode(nodes, names, []string{"x", "d"})
+	assert.Equal(t, []*proto.Node{expected[2], expected[0], expected[1], expected[3]}, nodes)
+	assert.Equal(t, [][]string{{"b", "d"}, nil, {"a"}, {"c"}}, names)
+}
+
+func TestPaginatedSync(t *testing.T) {
+	const limit = 1024
+
+	for _, mode := range []SyncMode{FullSync, DigestSync} {
+		t.Run(mode.String(), func(t *testing.T) {
+			god, ctl, err := newHealthyClusterGod(t, "tst", 1, 10, []sladder.EngineOption{
+				WithMaxSyncMessageSize(limit), WithSyncMode(mode),
+			}, nil)
+			assert.NoError(t, err)
+			defer god.Detach(ctl)
+			if !assert.True(t, god.AllViewpointConsist(true, true)) {
+				t.FailNow()
+			}
+
+			vps, records := god.VPList(), []*syncRecordTransport{}
+			for idx, vp := range vps {
+				assert.Equal(t, limit, vp.engine.maxSyncMessageSize)
+				record := &syncRecordTransport{Transport: vp.engine.transport}
+				vp.engine.transport = record
+				records = append(records, record)
+
+				m := vp.engine.WrapVersionKVValidator(sladder.StringValidator{})
+				assert.NoError(t, vp.cv.RegisterKey("key1", m, true, 0))
+				assert.NoError(t, vp.cv.Txn(func(tx *sladder.Transaction) bool {
+					rtx, err := tx.KV(vp.cv.Self(), "key1")
+					if !assert.NoError(t, err) {
+						return false
+					}
+					rtx.(*sladder.StringTxn).Set(strings.Repeat(strconv.FormatInt(int64(idx), 10), 256))
+					return true
+				}))
+			}
+
+			consistAt := syncLoop(t, vps, 500, func(round int) bool {
+				return !god.AllViewpointConsist(true, true)
+			}, false, false, false, nil)
+			assert.Less(t, consistAt, 500, "node entry cannot be consist within 500 round.")
+			t.Log("cluster is consist at round", consistAt)
+
+			paginated := false
+			for _, record := range records {
+				record.lock.Lock()
+				assert.LessOrEqual(t, record.maxSize, limit)
+				paginated = paginated || record.pages > 1
+				record.lock.Unlock()
+			}
+			assert.True(t, paginated)
+		})
+	}
+}
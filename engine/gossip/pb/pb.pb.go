@@ -79,12 +79,62 @@ func (GossipMessage_Type) EnumDescriptor() ([]byte, []int) {
 	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{0, 0}
 }
 
+type GossipMessage_Codec int32
+
+const (
+	GossipMessage_Raw   GossipMessage_Codec = 0
+	GossipMessage_Flate GossipMessage_Codec = 1 // value of body is compressed by DEFLATE.
+)
+
+// Enum value maps for GossipMessage_Codec.
+var (
+	GossipMessage_Codec_name = map[int32]string{
+		0: "Raw",
+		1: "Flate",
+	}
+	GossipMessage_Codec_value = map[string]int32{
+		"Raw":   0,
+		"Flate": 1,
+	}
+)
+
+func (x GossipMessage_Codec) Enum() *GossipMessage_Codec {
+	p := new(GossipMessage_Codec)
+	*p = x
+	return p
+}
+
+func (x GossipMessage_Codec) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GossipMessage_Codec) Descriptor() protoreflect.EnumDescriptor {
+	return file_engine_gossip_pb_pb_proto_enumTypes[1].Descriptor()
+}
+
+func (GossipMessage_Codec) Type() protoreflect.EnumType {
+	return &file_engine_gossip_pb_pb_proto_enumTypes[1]
+}
+
+func (x GossipMessage_Codec) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GossipMessage_Codec.Descriptor instead.
+func (GossipMessage_Codec) EnumDescriptor() ([]byte, []int) {
+	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{0, 1}
+}
+
 type Sync_Type int32
 
 const (
-	Sync_Unknown  Sync_Type = 0
-	Sync_PushPull Sync_Type = 1
-	Sync_Push     Sync_Type = 2
+	Sync_Unknown        Sync_Type = 0
+	Sync_PushPull       Sync_Type = 1
+	Sync_Push           Sync_Type = 2
+	Sync_Digest         Sync_Type = 3 // node digests only.
+	Sync_DigestPushPull Sync_Type = 4 // nodes differ from digests, with digests of nodes wanted.
+	Sync_DigestPush     Sync_Type = 5 // nodes wanted.
+	Sync_Eager          Sync_Type = 6 // local changes pushed eagerly.
 )
 
 // Enum value maps for Sync_Type.
@@ -93,11 +143,19 @@ var (
 		0: "Unknown",
 		1: "PushPull",
 		2: "Push",
+		3: "Digest",
+		4: "DigestPushPull",
+		5: "DigestPush",
+		6: "Eager",
 	}
 	Sync_Type_value = map[string]int32{
-		"Unknown":  0,
-		"PushPull": 1,
-		"Push":     2,
+		"Unknown":        0,
+		"PushPull":       1,
+		"Push":           2,
+		"Digest":         3,
+		"DigestPushPull": 4,
+		"DigestPush":     5,
+		"Eager":          6,
 	}
 )
 
@@ -112,11 +170,11 @@ func (x Sync_Type) String() string {
 }
 
 func (Sync_Type) Descriptor() protoreflect.EnumDescriptor {
-	return file_engine_gossip_pb_pb_proto_enumTypes[1].Descriptor()
+	return file_engine_gossip_pb_pb_proto_enumTypes[2].Descriptor()
 }
 
 func (Sync_Type) Type() protoreflect.EnumType {
-	return &file_engine_gossip_pb_pb_proto_enumTypes[1]
+	return &file_engine_gossip_pb_pb_proto_enumTypes[2]
 }
 
 func (x Sync_Type) Number() protoreflect.EnumNumber {
@@ -125,7 +183,7 @@ func (x Sync_Type) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Sync_Type.Descriptor instead.
 func (Sync_Type) EnumDescriptor() ([]byte, []int) {
-	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{4, 0}
+	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{6, 0}
 }
 
 // GossipMessage is container of message body.
@@ -134,8 +192,9 @@ type GossipMessage struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Type GossipMessage_Type `protobuf:"varint,1,opt,name=type,proto3,enum=pb.GossipMessage_Type" json:"type,omitempty"`
-	Body *any.Any           `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	Type  GossipMessage_Type  `protobuf:"varint,1,opt,name=type,proto3,enum=pb.GossipMessage_Type" json:"type,omitempty"`
+	Body  *any.Any            `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	Codec GossipMessage_Codec `protobuf:"varint,3,opt,name=codec,proto3,enum=pb.GossipMessage_Codec" json:"codec,omitempty"`
 }
 
 func (x *GossipMessage) Reset() {
@@ -184,19 +243,83 @@ func (x *GossipMessage) GetBody() *any.Any {
 	return nil
 }
 
+func (x *GossipMessage) GetCodec() GossipMessage_Codec {
+	if x != nil {
+		return x.Codec
+	}
+	return GossipMessage_Raw
+}
+
+// SWIM tag update of node, piggybacked on failure detector messages.
+type TagUpdate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Names []string `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	Tag   string   `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"` // encoded SWIM tag.
+}
+
+func (x *TagUpdate) Reset() {
+	*x = TagUpdate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_engine_gossip_pb_pb_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TagUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagUpdate) ProtoMessage() {}
+
+func (x *TagUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_gossip_pb_pb_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagUpdate.ProtoReflect.Descriptor instead.
+func (*TagUpdate) Descriptor() ([]byte, []int) {
+	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TagUpdate) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *TagUpdate) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
 // Ping request.
 type Ping struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Id      uint64       `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Updates []*TagUpdate `protobuf:"bytes,2,rep,name=updates,proto3" json:"updates,omitempty"`
 }
 
 func (x *Ping) Reset() {
 	*x = Ping{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_engine_gossip_pb_pb_proto_msgTypes[1]
+		mi := &file_engine_gossip_pb_pb_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -209,7 +332,7 @@ func (x *Ping) String() string {
 func (*Ping) ProtoMessage() {}
 
 func (x *Ping) ProtoReflect() protoreflect.Message {
-	mi := &file_engine_gossip_pb_pb_proto_msgTypes[1]
+	mi := &file_engine_gossip_pb_pb_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -222,7 +345,7 @@ func (x *Ping) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Ping.ProtoReflect.Descriptor instead.
 func (*Ping) Descriptor() ([]byte, []int) {
-	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{1}
+	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Ping) GetId() uint64 {
@@ -232,20 +355,28 @@ func (x *Ping) GetId() uint64 {
 	return 0
 }
 
+func (x *Ping) GetUpdates() []*TagUpdate {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
 // Ping acknowledge
 type Ack struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id            uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	NamesProxyFor []string `protobuf:"bytes,2,rep,name=names_proxy_for,json=namesProxyFor,proto3" json:"names_proxy_for,omitempty"`
+	Id            uint64       `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	NamesProxyFor []string     `protobuf:"bytes,2,rep,name=names_proxy_for,json=namesProxyFor,proto3" json:"names_proxy_for,omitempty"`
+	Updates       []*TagUpdate `protobuf:"bytes,3,rep,name=updates,proto3" json:"updates,omitempty"`
 }
 
 func (x *Ack) Reset() {
 	*x = Ack{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_engine_gossip_pb_pb_proto_msgTypes[2]
+		mi := &file_engine_gossip_pb_pb_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -258,7 +389,7 @@ func (x *Ack) String() string {
 func (*Ack) ProtoMessage() {}
 
 func (x *Ack) ProtoReflect() protoreflect.Message {
-	mi := &file_engine_gossip_pb_pb_proto_msgTypes[2]
+	mi := &file_engine_gossip_pb_pb_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -271,7 +402,7 @@ func (x *Ack) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Ack.ProtoReflect.Descriptor instead.
 func (*Ack) Descriptor() ([]byte, []int) {
-	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{2}
+	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Ack) GetId() uint64 {
@@ -288,20 +419,28 @@ func (x *Ack) GetNamesProxyFor() []string {
 	return nil
 }
 
+func (x *Ack) GetUpdates() []*TagUpdate {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
 // PingReq request.
 type PingReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id   uint64   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name []string `protobuf:"bytes,2,rep,name=name,proto3" json:"name,omitempty"`
+	Id      uint64       `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name    []string     `protobuf:"bytes,2,rep,name=name,proto3" json:"name,omitempty"`
+	Updates []*TagUpdate `protobuf:"bytes,3,rep,name=updates,proto3" json:"updates,omitempty"`
 }
 
 func (x *PingReq) Reset() {
 	*x = PingReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_engine_gossip_pb_pb_proto_msgTypes[3]
+		mi := &file_engine_gossip_pb_pb_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -314,7 +453,7 @@ func (x *PingReq) String() string {
 func (*PingReq) ProtoMessage() {}
 
 func (x *PingReq) ProtoReflect() protoreflect.Message {
-	mi := &file_engine_gossip_pb_pb_proto_msgTypes[3]
+	mi := &file_engine_gossip_pb_pb_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -327,7 +466,7 @@ func (x *PingReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PingReq.ProtoReflect.Descriptor instead.
 func (*PingReq) Descriptor() ([]byte, []int) {
-	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{3}
+	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *PingReq) GetId() uint64 {
@@ -344,6 +483,85 @@ func (x *PingReq) GetName() []string {
 	return nil
 }
 
+func (x *PingReq) GetUpdates() []*TagUpdate {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
+// Digest of node.
+type NodeDigest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Names      []string `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	Version    uint32   `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`       // SWIM tag version.
+	Hash       uint64   `protobuf:"varint,3,opt,name=hash,proto3" json:"hash,omitempty"`             // hash of entries.
+	Generation uint64   `protobuf:"varint,4,opt,name=generation,proto3" json:"generation,omitempty"` // SWIM tag boot generation.
+}
+
+func (x *NodeDigest) Reset() {
+	*x = NodeDigest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_engine_gossip_pb_pb_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NodeDigest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeDigest) ProtoMessage() {}
+
+func (x *NodeDigest) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_gossip_pb_pb_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeDigest.ProtoReflect.Descriptor instead.
+func (*NodeDigest) Descriptor() ([]byte, []int) {
+	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *NodeDigest) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *NodeDigest) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *NodeDigest) GetHash() uint64 {
+	if x != nil {
+		return x.Hash
+	}
+	return 0
+}
+
+func (x *NodeDigest) GetGeneration() uint64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
 // Cluster member synchronization.
 type Sync struct {
 	state         protoimpl.MessageState
@@ -353,12 +571,16 @@ type Sync struct {
 	Id      uint64          `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	Cluster *proto1.Cluster `protobuf:"bytes,2,opt,name=cluster,proto3" json:"cluster,omitempty"`
 	Type    Sync_Type       `protobuf:"varint,3,opt,name=type,proto3,enum=pb.Sync_Type" json:"type,omitempty"`
+	Digests []*NodeDigest   `protobuf:"bytes,4,rep,name=digests,proto3" json:"digests,omitempty"`
+	// pagination. message is partial when pages > 1.
+	Page  uint32 `protobuf:"varint,5,opt,name=page,proto3" json:"page,omitempty"`
+	Pages uint32 `protobuf:"varint,6,opt,name=pages,proto3" json:"pages,omitempty"`
 }
 
 func (x *Sync) Reset() {
 	*x = Sync{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_engine_gossip_pb_pb_proto_msgTypes[4]
+		mi := &file_engine_gossip_pb_pb_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -371,7 +593,7 @@ func (x *Sync) String() string {
 func (*Sync) ProtoMessage() {}
 
 func (x *Sync) ProtoReflect() protoreflect.Message {
-	mi := &file_engine_gossip_pb_pb_proto_msgTypes[4]
+	mi := &file_engine_gossip_pb_pb_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -384,7 +606,7 @@ func (x *Sync) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Sync.ProtoReflect.Descriptor instead.
 func (*Sync) Descriptor() ([]byte, []int) {
-	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{4}
+	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *Sync) GetId() uint64 {
@@ -408,6 +630,85 @@ func (x *Sync) GetType() Sync_Type {
 	return Sync_Unknown
 }
 
+func (x *Sync) GetDigests() []*NodeDigest {
+	if x != nil {
+		return x.Digests
+	}
+	return nil
+}
+
+func (x *Sync) GetPage() uint32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *Sync) GetPages() uint32 {
+	if x != nil {
+		return x.Pages
+	}
+	return 0
+}
+
+// Sealed is envelope of encrypted GossipMessage.
+type Sealed struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// type of sealed message in plain, which is authenticated as additional data.
+	Type GossipMessage_Type `protobuf:"varint,1,opt,name=type,proto3,enum=pb.GossipMessage_Type" json:"type,omitempty"`
+	// nonce followed by ciphertext.
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (x *Sealed) Reset() {
+	*x = Sealed{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_engine_gossip_pb_pb_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Sealed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Sealed) ProtoMessage() {}
+
+func (x *Sealed) ProtoReflect() protoreflect.Message {
+	mi := &file_engine_gossip_pb_pb_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Sealed.ProtoReflect.Descriptor instead.
+func (*Sealed) Descriptor() ([]byte, []int) {
+	return file_engine_gossip_pb_pb_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Sealed) GetType() GossipMessage_Type {
+	if x != nil {
+		return x.Type
+	}
+	return GossipMessage_Ping
+}
+
+func (x *Sealed) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
 var File_engine_gossip_pb_pb_proto protoreflect.FileDescriptor
 
 var file_engine_gossip_pb_pb_proto_rawDesc = []byte{
@@ -415,38 +716,75 @@ var file_engine_gossip_pb_pb_proto_rawDesc = []byte{
 	0x70, 0x62, 0x2f, 0x70, 0x62, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a,
 	0x19, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
 	0x2f, 0x61, 0x6e, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x10, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x97, 0x01, 0x0a,
+	0x6f, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xe3, 0x01, 0x0a,
 	0x0d, 0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2a,
 	0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x70,
 	0x62, 0x2e, 0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e,
 	0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x28, 0x0a, 0x04, 0x62, 0x6f,
 	0x64, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
 	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x04,
-	0x62, 0x6f, 0x64, 0x79, 0x22, 0x30, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x08, 0x0a, 0x04,
-	0x50, 0x69, 0x6e, 0x67, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x63, 0x6b, 0x10, 0x01, 0x12,
-	0x08, 0x0a, 0x04, 0x53, 0x79, 0x6e, 0x63, 0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x69, 0x6e,
-	0x67, 0x52, 0x65, 0x71, 0x10, 0x03, 0x22, 0x16, 0x0a, 0x04, 0x50, 0x69, 0x6e, 0x67, 0x12, 0x0e,
-	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x22, 0x3d,
-	0x0a, 0x03, 0x41, 0x63, 0x6b, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x02, 0x69, 0x64, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x5f, 0x70,
-	0x72, 0x6f, 0x78, 0x79, 0x5f, 0x66, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d,
-	0x6e, 0x61, 0x6d, 0x65, 0x73, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x46, 0x6f, 0x72, 0x22, 0x2d, 0x0a,
-	0x07, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
-	0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x90, 0x01, 0x0a,
-	0x04, 0x53, 0x79, 0x6e, 0x63, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x02, 0x69, 0x64, 0x12, 0x28, 0x0a, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43,
-	0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x12,
-	0x21, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e,
-	0x70, 0x62, 0x2e, 0x53, 0x79, 0x6e, 0x63, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79,
-	0x70, 0x65, 0x22, 0x2b, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x6e,
-	0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x50, 0x75, 0x73, 0x68, 0x50,
-	0x75, 0x6c, 0x6c, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x50, 0x75, 0x73, 0x68, 0x10, 0x02, 0x42,
-	0x2f, 0x5a, 0x2d, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x72,
-	0x6f, 0x73, 0x73, 0x6d, 0x65, 0x73, 0x68, 0x2f, 0x73, 0x6c, 0x61, 0x64, 0x64, 0x65, 0x72, 0x2f,
-	0x65, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x2f, 0x67, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x2f, 0x70, 0x62,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x62, 0x6f, 0x64, 0x79, 0x12, 0x2d, 0x0a, 0x05, 0x63, 0x6f, 0x64, 0x65, 0x63, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x63, 0x52, 0x05, 0x63, 0x6f,
+	0x64, 0x65, 0x63, 0x22, 0x30, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x50,
+	0x69, 0x6e, 0x67, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x63, 0x6b, 0x10, 0x01, 0x12, 0x08,
+	0x0a, 0x04, 0x53, 0x79, 0x6e, 0x63, 0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x69, 0x6e, 0x67,
+	0x52, 0x65, 0x71, 0x10, 0x03, 0x22, 0x1b, 0x0a, 0x05, 0x43, 0x6f, 0x64, 0x65, 0x63, 0x12, 0x07,
+	0x0a, 0x03, 0x52, 0x61, 0x77, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x46, 0x6c, 0x61, 0x74, 0x65,
+	0x10, 0x01, 0x22, 0x33, 0x0a, 0x09, 0x54, 0x61, 0x67, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05,
+	0x6e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x67, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x74, 0x61, 0x67, 0x22, 0x3f, 0x0a, 0x04, 0x50, 0x69, 0x6e, 0x67, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x12,
+	0x27, 0x0a, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x61, 0x67, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52,
+	0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x22, 0x66, 0x0a, 0x03, 0x41, 0x63, 0x6b, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x12,
+	0x26, 0x0a, 0x0f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x5f, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x5f, 0x66,
+	0x6f, 0x72, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x50,
+	0x72, 0x6f, 0x78, 0x79, 0x46, 0x6f, 0x72, 0x12, 0x27, 0x0a, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x61,
+	0x67, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73,
+	0x22, 0x56, 0x0a, 0x07, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x27, 0x0a, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x61, 0x67, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52,
+	0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x22, 0x70, 0x0a, 0x0a, 0x4e, 0x6f, 0x64, 0x65,
+	0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x67, 0x65,
+	0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a,
+	0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x9f, 0x02, 0x0a, 0x04, 0x53,
+	0x79, 0x6e, 0x63, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x28, 0x0a, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6c, 0x75,
+	0x73, 0x74, 0x65, 0x72, 0x52, 0x07, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x12, 0x21, 0x0a,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x70, 0x62,
+	0x2e, 0x53, 0x79, 0x6e, 0x63, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x28, 0x0a, 0x07, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x62, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x44, 0x69, 0x67, 0x65, 0x73,
+	0x74, 0x52, 0x07, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61,
+	0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x70, 0x61, 0x67, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x70,
+	0x61, 0x67, 0x65, 0x73, 0x22, 0x66, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07,
+	0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x50, 0x75, 0x73,
+	0x68, 0x50, 0x75, 0x6c, 0x6c, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x50, 0x75, 0x73, 0x68, 0x10,
+	0x02, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x10, 0x03, 0x12, 0x12, 0x0a,
+	0x0e, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x50, 0x75, 0x73, 0x68, 0x50, 0x75, 0x6c, 0x6c, 0x10,
+	0x04, 0x12, 0x0e, 0x0a, 0x0a, 0x44, 0x69, 0x67, 0x65, 0x73, 0x74, 0x50, 0x75, 0x73, 0x68, 0x10,
+	0x05, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x61, 0x67, 0x65, 0x72, 0x10, 0x06, 0x22, 0x4e, 0x0a, 0x06,
+	0x53, 0x65, 0x61, 0x6c, 0x65, 0x64, 0x12, 0x2a, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x6f, 0x73, 0x73, 0x69, 0x70,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x2f, 0x5a, 0x2d,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x72, 0x6f, 0x73, 0x73,
+	0x6d, 0x65, 0x73, 0x68, 0x2f, 0x73, 0x6c, 0x61, 0x64, 0x64, 0x65, 0x72, 0x2f, 0x65, 0x6e, 0x67,
+	0x69, 0x6e, 0x65, 0x2f, 0x67, 0x6f, 0x73, 0x73, 0x69, 0x70, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -461,29 +799,39 @@ func file_engine_gossip_pb_pb_proto_rawDescGZIP() []byte {
 	return file_engine_gossip_pb_pb_proto_rawDescData
 }
 
-var file_engine_gossip_pb_pb_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_engine_gossip_pb_pb_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_engine_gossip_pb_pb_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_engine_gossip_pb_pb_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_engine_gossip_pb_pb_proto_goTypes = []interface{}{
-	(GossipMessage_Type)(0), // 0: pb.GossipMessage.Type
-	(Sync_Type)(0),          // 1: pb.Sync.Type
-	(*GossipMessage)(nil),   // 2: pb.GossipMessage
-	(*Ping)(nil),            // 3: pb.Ping
-	(*Ack)(nil),             // 4: pb.Ack
-	(*PingReq)(nil),         // 5: pb.PingReq
-	(*Sync)(nil),            // 6: pb.Sync
-	(*any.Any)(nil),         // 7: google.protobuf.Any
-	(*proto1.Cluster)(nil),  // 8: proto.Cluster
+	(GossipMessage_Type)(0),  // 0: pb.GossipMessage.Type
+	(GossipMessage_Codec)(0), // 1: pb.GossipMessage.Codec
+	(Sync_Type)(0),           // 2: pb.Sync.Type
+	(*GossipMessage)(nil),    // 3: pb.GossipMessage
+	(*TagUpdate)(nil),        // 4: pb.TagUpdate
+	(*Ping)(nil),             // 5: pb.Ping
+	(*Ack)(nil),              // 6: pb.Ack
+	(*PingReq)(nil),          // 7: pb.PingReq
+	(*NodeDigest)(nil),       // 8: pb.NodeDigest
+	(*Sync)(nil),             // 9: pb.Sync
+	(*Sealed)(nil),           // 10: pb.Sealed
+	(*any.Any)(nil),          // 11: google.protobuf.Any
+	(*proto1.Cluster)(nil),   // 12: proto.Cluster
 }
 var file_engine_gossip_pb_pb_proto_depIdxs = []int32{
-	0, // 0: pb.GossipMessage.type:type_name -> pb.GossipMessage.Type
-	7, // 1: pb.GossipMessage.body:type_name -> google.protobuf.Any
-	8, // 2: pb.Sync.cluster:type_name -> proto.Cluster
-	1, // 3: pb.Sync.type:type_name -> pb.Sync.Type
-	4, // [4:4] is the sub-list for method output_type
-	4, // [4:4] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	0,  // 0: pb.GossipMessage.type:type_name -> pb.GossipMessage.Type
+	11, // 1: pb.GossipMessage.body:type_name -> google.protobuf.Any
+	1,  // 2: pb.GossipMessage.codec:type_name -> pb.GossipMessage.Codec
+	4,  // 3: pb.Ping.updates:type_name -> pb.TagUpdate
+	4,  // 4: pb.Ack.updates:type_name -> pb.TagUpdate
+	4,  // 5: pb.PingReq.updates:type_name -> pb.TagUpdate
+	12, // 6: pb.Sync.cluster:type_name -> proto.Cluster
+	2,  // 7: pb.Sync.type:type_name -> pb.Sync.Type
+	8,  // 8: pb.Sync.digests:type_name -> pb.NodeDigest
+	0,  // 9: pb.Sealed.type:type_name -> pb.GossipMessage.Type
+	10, // [10:10] is the sub-list for method output_type
+	10, // [10:10] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_engine_gossip_pb_pb_proto_init() }
@@ -505,7 +853,7 @@ func file_engine_gossip_pb_pb_proto_init() {
 			}
 		}
 		file_engine_gossip_pb_pb_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Ping); i {
+			switch v := v.(*TagUpdate); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -517,7 +865,7 @@ func file_engine_gossip_pb_pb_proto_init() {
 			}
 		}
 		file_engine_gossip_pb_pb_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Ack); i {
+			switch v := v.(*Ping); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -529,7 +877,7 @@ func file_engine_gossip_pb_pb_proto_init() {
 			}
 		}
 		file_engine_gossip_pb_pb_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PingReq); i {
+			switch v := v.(*Ack); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -541,6 +889,30 @@ func file_engine_gossip_pb_pb_proto_init() {
 			}
 		}
 		file_engine_gossip_pb_pb_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PingReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_engine_gossip_pb_pb_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NodeDigest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_engine_gossip_pb_pb_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Sync); i {
 			case 0:
 				return &v.state
@@ -552,14 +924,26 @@ func file_engine_gossip_pb_pb_proto_init() {
 				return nil
 			}
 		}
+		file_engine_gossip_pb_pb_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Sealed); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_engine_gossip_pb_pb_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   5,
+			NumEnums:      3,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
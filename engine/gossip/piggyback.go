@@ -0,0 +1,162 @@
+package gossip
+
+import (
+	"math"
+	"sort"
+
+	"github.com/crossmesh/sladder"
+	pb "github.com/crossmesh/sladder/engine/gossip/pb"
+	"github.com/crossmesh/sladder/proto"
+)
+
+const (
+	defaultRetransmitMult   = 4
+	maxPiggybackedTagUpdate = 8 // maximum tag updates piggybacked on one message.
+)
+
+type tagUpdate struct {
+	msg       *pb.TagUpdate
+	transmits uint
+}
+
+// _queueTagUpdate queues SWIM tag update of node for piggybacking. The older one of the same node is replaced.
+func (e *EngineInstance) _queueTagUpdate(node *sladder.Node, names []string, tag string) {
+	if e.retransmitMult < 1 || len(names) < 1 {
+		return
+	}
+	e.tagUpdates[node] = &tagUpdate{
+		msg: &pb.TagUpdate{Names: names, Tag: tag},
+	}
+}
+
+func (e *EngineInstance) _retransmitLimit() uint {
+	n := 0
+	for _, nodes := range e.withRegion {
+		n += len(nodes)
+	}
+	return e.retransmitMult * uint(math.Ceil(math.Log10(float64(n+1))))
+}
+
+// _piggybackTagUpdates selects tag updates to piggyback. The less transmitted ones are preferred.
+func (e *EngineInstance) _piggybackTagUpdates(minc *FailureDetectorMetricIncrement) (msgs []*pb.TagUpdate) {
+	if len(e.tagUpdates) < 1 {
+		return nil
+	}
+
+	type candidate struct {
+		node   *sladder.Node
+		update *tagUpdate
+	}
+	candidates := make([]candidate, 0, len(e.tagUpdates))
+	for node, update := range e.tagUpdates {
+		candidates = append(candidates, candidate{node: node, update: update})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].update.transmits < candidates[j].update.transmits
+	})
+	if len(candidates) > maxPiggybackedTagUpdate {
+		candidates = candidates[:maxPiggybackedTagUpdate]
+	}
+
+	limit := e._retransmitLimit()
+	for _, c := range candidates {
+		msgs = append(msgs, c.update.msg)
+		if c.update.transmits++; c.update.transmits >= limit {
+			delete(e.tagUpdates, c.node)
+		}
+	}
+	minc.Piggybacked += uint64(len(msgs))
+
+	return
+}
+
+func (e *EngineInstance) piggybackTagUpdates() []*pb.TagUpdate {
+	minc := &FailureDetectorMetricIncrement{}
+	defer e.Metrics.FailureDetector.ApplyIncrement(minc)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e._piggybackTagUpdates(minc)
+}
+
+// applyTagUpdates merges piggybacked SWIM tag updates of known nodes.
+func (e *EngineInstance) applyTagUpdates(from []string, updates []*pb.TagUpdate) {
+	if len(updates) < 1 {
+		return
+	}
+
+	minc := &FailureDetectorMetricIncrement{}
+	defer e.Metrics.FailureDetector.ApplyIncrement(minc)
+	minc.IncomingPiggybacked += uint64(len(updates))
+
+	type staleUpdate struct {
+		node  *sladder.Node
+		names []string
+		raw   string
+	}
+	var (
+		fromNode  *sladder.Node
+		suspected map[*sladder.Node]uint32 // suspected nodes reported by the remote, with tag versions.
+		stales    []*staleUpdate           // newer local tags of nodes the remote lags behind.
+	)
+	if err := e.cluster.Txn(func(t *sladder.Transaction) bool {
+		// mark txn internal.
+		e.innerTxnIDs.Store(t.ID(), struct{}{})
+
+		fromNode, suspected, stales = t.MostPossibleNode(from), nil, nil
+		for _, update := range updates {
+			if update == nil || len(update.Names) < 1 {
+				continue
+			}
+			// new nodes are left to cluster sync, since a tag alone cannot make up a node.
+			node := t.MostPossibleNode(update.Names)
+			if node == nil || !t.KeyExists(node, e.swimTagKey) {
+				continue
+			}
+			tag := &SWIMTag{}
+			if err := tag.Decode(update.Tag); err != nil {
+				e.log.Warnf("drop a piggybacked invalid swim tag. (decode err = \"%v\").", err.Error())
+				continue
+			}
+			rtx, err := t.KV(node, e.swimTagKey)
+			if err != nil {
+				e.log.Warnf("cannot get swim tag. skip. (err = \"%v\") {node = %v}", err, node.PrintableName())
+				continue
+			}
+			if local := rtx.(*SWIMTagTxn); local.tag.Succeeds(tag) {
+				// the remote lags behind. spread the newer one again.
+				stales = append(stales, &staleUpdate{node: node, names: t.Names(node), raw: local.After()})
+				continue
+			}
+			if node != e.cluster.Self() && tag.State == SUSPECTED {
+				if suspected == nil {
+					suspected = make(map[*sladder.Node]uint32)
+				}
+				suspected[node] = tag.Version
+			}
+
+			if err := t.MergeNodeSnapshot(node, &proto.Node{
+				Kvs: []*proto.Node_KeyValue{{Key: e.swimTagKey, Value: update.Tag}},
+			}, false, true, true); err != nil {
+				e.log.Warnf("apply piggybacked swim tag failure. skip. (err = \"%v\") {node = %v}", err, node.PrintableName())
+				continue
+			}
+		}
+		return true
+	}); err != nil {
+		e.log.Warnf("failed to apply piggybacked swim tags. (err = \"%v\")", err.Error())
+		return
+	}
+	if len(stales) > 0 {
+		e.lock.Lock()
+		for _, stale := range stales {
+			e._queueTagUpdate(stale.node, stale.names, stale.raw)
+		}
+		e.lock.Unlock()
+	}
+	if fromNode != nil && len(suspected) > 0 {
+		// the remote confirms suspections.
+		e.confirmSuspections(fromNode, suspected)
+	}
+}
@@ -0,0 +1,107 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	"github.com/crossmesh/sladder/engine/gossip/simnet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPiggybackTagUpdates(t *testing.T) {
+	t.Run("retransmit", func(t *testing.T) {
+		e := New(simnet.New().Transport("default")).(*EngineInstance)
+		nodes := make(map[*sladder.Node]struct{})
+		for i := 0; i < 9; i++ {
+			nodes[&sladder.Node{}] = struct{}{}
+		}
+		e.withRegion[""] = nodes
+		limit := e._retransmitLimit()
+		assert.Equal(t, uint(defaultRetransmitMult), limit)
+
+		minc := &FailureDetectorMetricIncrement{}
+		assert.Nil(t, e._piggybackTagUpdates(minc))
+
+		for i := 0; i < maxPiggybackedTagUpdate; i++ {
+			e._queueTagUpdate(&sladder.Node{}, []string{"n"}, "{}")
+		}
+		e._piggybackTagUpdates(minc)
+		fresh := &sladder.Node{}
+		e._queueTagUpdate(fresh, []string{"fresh"}, "{}")
+		e._queueTagUpdate(&sladder.Node{}, nil, "{}") // anonymous.
+
+		// less transmitted updates are preferred.
+		msgs := e._piggybackTagUpdates(minc)
+		assert.Equal(t, maxPiggybackedTagUpdate, len(msgs))
+		assert.Equal(t, []string{"fresh"}, msgs[0].Names)
+
+		// each update is transmitted for limited times.
+		for i := 0; i < 10 && len(e.tagUpdates) > 0; i++ {
+			e._piggybackTagUpdates(minc)
+		}
+		assert.Equal(t, 0, len(e.tagUpdates))
+		assert.Equal(t, uint64((maxPiggybackedTagUpdate+1)*limit), minc.Piggybacked)
+
+		// disabled.
+		e = New(simnet.New().Transport("default"), WithRetransmitMult(0)).(*EngineInstance)
+		e._queueTagUpdate(&sladder.Node{}, []string{"n"}, "{}")
+		assert.Equal(t, 0, len(e.tagUpdates))
+	})
+
+	t.Run("refutation", func(t *testing.T) {
+		god, ctl, err := newHealthyClusterGod(t, "pb-tst", 1, 6, []sladder.EngineOption{
+			// more retransmissions make infection less probabilistic.
+			WithGossipPeriod(time.Second), WithRetransmitMult(8),
+		}, nil)
+		assert.NoError(t, err)
+		defer god.Detach(ctl)
+
+		vps := god.VPList()
+		claimer, target := vps[0], vps[1]
+		targetNames := target.cv.Self().Names()
+		oldVersion := uint32(0)
+		assert.NoError(t, claimer.cv.Txn(func(tx *sladder.Transaction) bool {
+			rtx, err := tx.KV(tx.MostPossibleNode(targetNames), claimer.engine.swimTagKey)
+			if !assert.NoError(t, err) {
+				return false
+			}
+			tag := rtx.(*SWIMTagTxn)
+			oldVersion = tag.Version()
+			return tag.ClaimSuspected()
+		}))
+
+		// no cluster sync. states spread only by probes.
+		consistAt := syncLoop(t, vps, 50, func(round int) bool {
+			time.Sleep(time.Millisecond * 20)
+			refuted := true
+			for _, vp := range vps {
+				vp.cv.Txn(func(tx *sladder.Transaction) bool {
+					rtx, err := tx.KV(tx.MostPossibleNode(targetNames), vp.engine.swimTagKey)
+					if assert.NoError(t, err) {
+						tag := rtx.(*SWIMTagTxn)
+						if tag.State() != ALIVE || tag.Version() <= oldVersion {
+							refuted = false
+						}
+					}
+					return false
+				})
+			}
+			return !refuted
+		}, false, false, false, func(e *EngineInstance) {
+			e.DetectFailure()
+		})
+		if consistAt >= 50 {
+			dumpViewPoint(t, vps, true, true)
+		}
+		assert.Less(t, consistAt, 50, "refutation doesn't spread within 50 probe rounds.")
+
+		piggybacked := uint64(0)
+		for _, vp := range vps {
+			vp.engine.Metrics.FailureDetector.lock.Lock()
+			piggybacked += vp.engine.Metrics.FailureDetector.IncomingPiggybacked
+			vp.engine.Metrics.FailureDetector.lock.Unlock()
+		}
+		assert.Greater(t, piggybacked, uint64(0))
+	})
+}
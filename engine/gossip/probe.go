@@ -0,0 +1,91 @@
+package gossip
+
+import (
+	"math/rand"
+
+	"github.com/crossmesh/sladder"
+)
+
+// probeList is randomized round-robin probe list described in SWIM.
+// Members are shuffled once and probed in order, so every member is probed within one pass,
+// which bounds worst-case failure detection time.
+type probeList struct {
+	nodes []*sladder.Node
+	index map[*sladder.Node]int
+	next  int
+}
+
+func (l *probeList) Len() int { return len(l.nodes) }
+
+func (l *probeList) shuffle() {
+	rand.Shuffle(len(l.nodes), func(i, j int) {
+		l.nodes[i], l.nodes[j] = l.nodes[j], l.nodes[i]
+		l.index[l.nodes[i]], l.index[l.nodes[j]] = i, j
+	})
+	l.next = 0
+}
+
+// insert puts node at random position among nodes not probed in this pass.
+func (l *probeList) insert(node *sladder.Node) {
+	if l.index == nil {
+		l.index = make(map[*sladder.Node]int)
+	}
+	if _, exists := l.index[node]; exists {
+		return
+	}
+	n := len(l.nodes)
+	l.nodes = append(l.nodes, node)
+	l.index[node] = n
+
+	// swap with a random one not probed in this pass, so that no node is probed twice within a pass.
+	if i := l.next + rand.Intn(n+1-l.next); i != n {
+		l.nodes[i], l.nodes[n] = l.nodes[n], l.nodes[i]
+		l.index[l.nodes[i]], l.index[l.nodes[n]] = i, n
+	}
+}
+
+func (l *probeList) remove(node *sladder.Node) {
+	i, exists := l.index[node]
+	if !exists {
+		return
+	}
+	delete(l.index, node)
+
+	// keep order of the rest.
+	copy(l.nodes[i:], l.nodes[i+1:])
+	l.nodes[len(l.nodes)-1] = nil
+	l.nodes = l.nodes[:len(l.nodes)-1]
+	for j := i; j < len(l.nodes); j++ {
+		l.index[l.nodes[j]] = j
+	}
+	if i < l.next {
+		l.next--
+	}
+}
+
+// pick returns next n distinct nodes to probe. List is reshuffled after each pass.
+func (l *probeList) pick(n int) (nodes []*sladder.Node) {
+	if n > len(l.nodes) {
+		n = len(l.nodes)
+	}
+	for len(nodes) < n {
+		if l.next >= len(l.nodes) {
+			l.shuffle()
+		}
+		node := l.nodes[l.next]
+		l.next++
+
+		// after reshuffle, node picked in previous pass may come again.
+		picked := false
+		for _, p := range nodes {
+			if p == node {
+				picked = true
+				break
+			}
+		}
+		if !picked {
+			nodes = append(nodes, node)
+		}
+	}
+	return
+}
@@ -0,0 +1,121 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeList(t *testing.T) {
+	t.Run("round_robin", func(t *testing.T) {
+		l := &probeList{}
+		assert.Equal(t, 0, len(l.pick(3)))
+
+		nodes := make(map[*sladder.Node]struct{})
+		for i := 0; i < 10; i++ {
+			node := &sladder.Node{}
+			nodes[node] = struct{}{}
+			l.insert(node)
+			l.insert(node) // duplicated.
+		}
+		assert.Equal(t, 10, l.Len())
+
+		for pass := 0; pass < 5; pass++ {
+			probed := make(map[*sladder.Node]struct{})
+			for i := 0; i < 5; i++ {
+				for _, node := range l.pick(2) {
+					probed[node] = struct{}{}
+				}
+			}
+			assert.Equal(t, nodes, probed, "not all nodes probed within one pass.")
+		}
+
+		// distinct nodes.
+		picked := l.pick(20)
+		assert.Equal(t, 10, len(picked))
+		probed := make(map[*sladder.Node]struct{})
+		for _, node := range picked {
+			probed[node] = struct{}{}
+		}
+		assert.Equal(t, nodes, probed)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		l := &probeList{}
+		var nodes []*sladder.Node
+		for i := 0; i < 6; i++ {
+			node := &sladder.Node{}
+			nodes = append(nodes, node)
+			l.insert(node)
+		}
+		l.pick(3)
+		probed := l.nodes[0]
+		remain := append([]*sladder.Node{}, l.nodes[l.next:]...)
+
+		// removal of probed node doesn't skip the rest.
+		l.remove(probed)
+		l.remove(probed)
+		assert.Equal(t, 5, l.Len())
+		assert.Equal(t, remain, l.pick(3))
+		for i, node := range l.nodes {
+			assert.Equal(t, i, l.index[node])
+		}
+
+		// inserted node will be probed within next pass.
+		node := &sladder.Node{}
+		l.insert(node)
+		found := false
+		for i := 0; i < l.Len()*2 && !found; i++ {
+			found = l.pick(1)[0] == node
+		}
+		assert.True(t, found)
+		for i, node := range l.nodes {
+			assert.Equal(t, i, l.index[node])
+		}
+
+		// insertion disturbs only nodes not probed in this pass.
+		l.pick(2)
+		probedNodes := append([]*sladder.Node{}, l.nodes[:l.next]...)
+		for i := 0; i < 20; i++ {
+			node := &sladder.Node{}
+			l.insert(node)
+			assert.Equal(t, probedNodes, l.nodes[:l.next])
+			assert.GreaterOrEqual(t, l.index[node], l.next)
+		}
+	})
+}
+
+func TestRoundRobinProbe(t *testing.T) {
+	god, ctl, err := newHealthyClusterGod(t, "probe-tst", 1, 8, []sladder.EngineOption{
+		WithGossipPeriod(time.Second), WithFanout(1),
+	}, nil)
+	assert.NoError(t, err)
+	defer god.Detach(ctl)
+
+	for _, vp := range god.VPList() {
+		e := vp.engine
+		e.lock.RLock()
+		assert.Equal(t, 7, e.probes.Len())
+		_, self := e.probes.index[vp.cv.Self()]
+		assert.False(t, self)
+		e.lock.RUnlock()
+
+		// all peers are probed within 7 ticks.
+		e.Metrics.FailureDetector.lock.Lock()
+		before := e.Metrics.FailureDetector.Success
+		e.Metrics.FailureDetector.lock.Unlock()
+		for i := 0; i < 7; i++ {
+			e.DetectFailure()
+		}
+		assert.Eventually(t, func() bool {
+			e.Metrics.FailureDetector.lock.Lock()
+			defer e.Metrics.FailureDetector.lock.Unlock()
+			return e.Metrics.FailureDetector.Success-before >= 7
+		}, time.Second, time.Millisecond*10)
+		e.lock.RLock()
+		assert.Equal(t, 0, len(e.inPing))
+		e.lock.RUnlock()
+	}
+}
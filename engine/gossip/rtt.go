@@ -0,0 +1,71 @@
+package gossip
+
+import (
+	"time"
+
+	"github.com/crossmesh/sladder"
+)
+
+const (
+	rttGainShift          = 3 // smoothed round-trip time gain is 1/8.
+	rttVarianceGainShift  = 2 // round-trip time variance gain is 1/4.
+	rttVarianceMultiplier = 4
+
+	minRoundTripTimeout          = time.Millisecond * 10
+	maxRoundTripTimeoutTimes     = 10 // maximum round-trip timeout in gossip periods.
+	defaultRoundTripTimeoutTimes = 2  // round-trip timeout in gossip periods before any measurement.
+)
+
+// roundTripEstimator estimates round-trip time of a peer in the style of Jacobson/Karels.
+type roundTripEstimator struct {
+	srtt    time.Duration // smoothed round-trip time.
+	rttvar  time.Duration // round-trip time variance.
+	samples uint
+}
+
+func (r *roundTripEstimator) update(sample time.Duration) {
+	if sample < 0 {
+		sample = 0
+	}
+	if r.samples < 1 {
+		r.srtt, r.rttvar = sample, sample/2
+	} else {
+		delta := r.srtt - sample
+		if delta < 0 {
+			delta = -delta
+		}
+		r.rttvar += (delta - r.rttvar) >> rttVarianceGainShift
+		r.srtt += (sample - r.srtt) >> rttGainShift
+	}
+	r.samples++
+}
+
+func (r *roundTripEstimator) timeout() time.Duration {
+	return r.srtt + rttVarianceMultiplier*r.rttvar
+}
+
+func (e *EngineInstance) _traceRoundTrip(node *sladder.Node, sample time.Duration) {
+	r, _ := e.roundTrips[node]
+	if r == nil {
+		r = &roundTripEstimator{}
+		e.roundTrips[node] = r
+	}
+	r.update(sample)
+}
+
+// _roundTripTimeout returns timeout of a round trip to node, derived from estimated round-trip time.
+func (e *EngineInstance) _roundTripTimeout(node *sladder.Node) time.Duration {
+	period := e.getGossipPeriod()
+
+	r, _ := e.roundTrips[node]
+	if r == nil || r.samples < 1 {
+		return period * defaultRoundTripTimeoutTimes
+	}
+	timeout := r.timeout()
+	if timeout < minRoundTripTimeout {
+		timeout = minRoundTripTimeout
+	} else if max := period * maxRoundTripTimeoutTimes; timeout > max {
+		timeout = max
+	}
+	return timeout
+}
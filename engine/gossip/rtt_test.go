@@ -0,0 +1,91 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	"github.com/crossmesh/sladder/engine/gossip/simnet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundTripEstimator(t *testing.T) {
+	r := &roundTripEstimator{}
+	r.update(time.Millisecond * 100)
+	assert.Equal(t, time.Millisecond*100, r.srtt)
+	assert.Equal(t, time.Millisecond*50, r.rttvar)
+	assert.Equal(t, time.Millisecond*300, r.timeout())
+
+	// converges to stable round trip, with variance vanishing.
+	for i := 0; i < 100; i++ {
+		r.update(time.Millisecond * 20)
+	}
+	assert.InDelta(t, float64(time.Millisecond*20), float64(r.srtt), float64(time.Millisecond))
+	assert.Less(t, int64(r.rttvar), int64(time.Millisecond))
+
+	// jitter raises variance.
+	stable := r.timeout()
+	for i := 0; i < 10; i++ {
+		if i%2 == 0 {
+			r.update(time.Millisecond * 5)
+		} else {
+			r.update(time.Millisecond * 35)
+		}
+	}
+	assert.Greater(t, int64(r.timeout()), int64(stable))
+	assert.Greater(t, int64(r.rttvar), int64(time.Millisecond*5))
+}
+
+func TestRoundTripTimeout(t *testing.T) {
+	t.Run("bounds", func(t *testing.T) {
+		e := New(simnet.New().Transport("default"), WithGossipPeriod(time.Second)).(*EngineInstance)
+		node := &sladder.Node{}
+		assert.Equal(t, time.Second*defaultRoundTripTimeoutTimes, e._roundTripTimeout(node))
+
+		e._traceRoundTrip(node, time.Microsecond)
+		assert.Equal(t, minRoundTripTimeout, e._roundTripTimeout(node))
+
+		e._traceRoundTrip(node, time.Minute)
+		assert.Equal(t, time.Second*maxRoundTripTimeoutTimes, e._roundTripTimeout(node))
+	})
+
+	t.Run("measured", func(t *testing.T) {
+		latency := time.Millisecond * 30
+
+		god, ctl, err := newHealthyClusterGod(t, "rtt-tst", 1, 2, []sladder.EngineOption{
+			WithGossipPeriod(time.Second),
+		}, nil)
+		assert.NoError(t, err)
+		defer god.Detach(ctl)
+		ctl.SetFaults(&simnet.Faults{Latency: simnet.ConstantLatency(latency)})
+
+		vps := god.VPList()
+		for i := 0; i < 5; i++ {
+			for _, vp := range vps {
+				vp.engine.DetectFailure()
+			}
+			time.Sleep(latency * 4)
+		}
+
+		for _, vp := range vps {
+			var peer *sladder.Node
+			vp.cv.RangeNodes(func(node *sladder.Node) bool {
+				if node != vp.cv.Self() {
+					peer = node
+				}
+				return true
+			}, false, false)
+			if !assert.NotNil(t, peer) {
+				continue
+			}
+
+			vp.engine.lock.RLock()
+			timeout := vp.engine._roundTripTimeout(peer)
+			vp.engine.lock.RUnlock()
+
+			// round trip takes 2 * latency.
+			assert.GreaterOrEqual(t, int64(timeout), int64(latency*2))
+			assert.Less(t, int64(timeout), int64(time.Second*defaultRoundTripTimeoutTimes))
+		}
+	})
+}
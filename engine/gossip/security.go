@@ -0,0 +1,288 @@
+package gossip
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/crossmesh/sladder"
+	"github.com/crossmesh/sladder/engine/gossip/pb"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrInvalidKeySize        = errors.New("key size should be 16, 24 or 32 bytes")
+	ErrKeyNotFound           = errors.New("key not found in keyring")
+	ErrRemovePrimaryKey      = errors.New("primary key cannot be removed")
+	ErrMessageAuthentication = errors.New("message authentication failed")
+)
+
+type keyringEntry struct {
+	key  []byte
+	aead cipher.AEAD
+}
+
+// Keyring holds AES-GCM keys to seal and open gossip messages.
+// Messages are sealed with the primary key, and can be opened with any key in keyring.
+//
+// To rotate key without interruption, install new key to all nodes by AddKey(), then switch primary
+// key of all nodes to the new one by UseKey(). Old key can be removed after all nodes are switched.
+type Keyring struct {
+	lock    sync.RWMutex
+	entries []*keyringEntry // primary key first.
+}
+
+func newKeyringEntry(key []byte) (*keyringEntry, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, ErrInvalidKeySize
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &keyringEntry{
+		key:  append([]byte(nil), key...),
+		aead: aead,
+	}, nil
+}
+
+// NewKeyring creates keyring with primary key and other keys.
+func NewKeyring(primary []byte, keys ...[]byte) (*Keyring, error) {
+	k := &Keyring{}
+	if err := k.AddKey(primary); err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if err := k.AddKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+func (k *Keyring) indexOf(key []byte) int {
+	for idx, entry := range k.entries {
+		if bytes.Equal(entry.key, key) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// AddKey installs key to keyring.
+// The first installed key becomes primary key.
+func (k *Keyring) AddKey(key []byte) error {
+	entry, err := newKeyringEntry(key)
+	if err != nil {
+		return err
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if k.indexOf(key) < 0 {
+		k.entries = append(k.entries, entry)
+	}
+	return nil
+}
+
+// UseKey switches primary key to an installed key.
+func (k *Keyring) UseKey(key []byte) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	idx := k.indexOf(key)
+	if idx < 0 {
+		return ErrKeyNotFound
+	}
+	k.entries[0], k.entries[idx] = k.entries[idx], k.entries[0]
+	return nil
+}
+
+// RemoveKey removes non-primary key from keyring.
+func (k *Keyring) RemoveKey(key []byte) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	idx := k.indexOf(key)
+	if idx < 0 {
+		return ErrKeyNotFound
+	}
+	if idx == 0 {
+		return ErrRemovePrimaryKey
+	}
+	k.entries = append(k.entries[:idx], k.entries[idx+1:]...)
+	return nil
+}
+
+// PrimaryKey returns primary key.
+func (k *Keyring) PrimaryKey() []byte {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	if len(k.entries) < 1 {
+		return nil
+	}
+	return append([]byte(nil), k.entries[0].key...)
+}
+
+// Keys returns all keys. The first one is primary key.
+func (k *Keyring) Keys() (keys [][]byte) {
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	for _, entry := range k.entries {
+		keys = append(keys, append([]byte(nil), entry.key...))
+	}
+	return
+}
+
+// sealedAdditionalData returns encoded message type, which is authenticated along with sealed message.
+func sealedAdditionalData(ty pb.GossipMessage_Type) []byte {
+	return protowire.AppendVarint(nil, uint64(ty))
+}
+
+// Seal encrypts raw pb.GossipMessage to raw pb.Sealed.
+func (k *Keyring) Seal(raw []byte) ([]byte, error) {
+	var msg pb.GossipMessage
+	if err := proto.Unmarshal(raw, &msg); err != nil {
+		return nil, err
+	}
+
+	k.lock.RLock()
+	if len(k.entries) < 1 {
+		k.lock.RUnlock()
+		return nil, ErrKeyNotFound
+	}
+	aead := k.entries[0].aead
+	k.lock.RUnlock()
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(raw)+aead.Overhead())
+	if _, err := io.ReadFull(crand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("cannot generate nonce: %v", err)
+	}
+	sealed := &pb.Sealed{
+		Type:    msg.Type,
+		Payload: aead.Seal(nonce, nonce, raw, sealedAdditionalData(msg.Type)),
+	}
+	return proto.Marshal(sealed)
+}
+
+// Open authenticates and decrypts raw pb.Sealed to raw pb.GossipMessage.
+func (k *Keyring) Open(raw []byte) ([]byte, error) {
+	var sealed pb.Sealed
+	if err := proto.Unmarshal(raw, &sealed); err != nil {
+		return nil, err
+	}
+	ad := sealedAdditionalData(sealed.Type)
+
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
+	for _, entry := range k.entries {
+		nonceSize := entry.aead.NonceSize()
+		if len(sealed.Payload) < nonceSize+entry.aead.Overhead() {
+			continue
+		}
+		plain, err := entry.aead.Open(nil, sealed.Payload[:nonceSize], sealed.Payload[nonceSize:], ad)
+		if err != nil {
+			continue
+		}
+		// ensure sealed type is not forged.
+		var msg pb.GossipMessage
+		if err = proto.Unmarshal(plain, &msg); err != nil || msg.Type != sealed.Type {
+			return nil, ErrMessageAuthentication
+		}
+		return plain, nil
+	}
+	return nil, ErrMessageAuthentication
+}
+
+// SealedTransport seals outgoing messages and authenticates incoming messages with keyring.
+// Incoming messages which fail to authenticate are dropped.
+//
+// Sealed message keeps message type in plain, so transports routing by message type still work.
+type SealedTransport struct {
+	Transport
+
+	keyring *Keyring
+	metrics *SecurityMetrics
+	log     sladder.Logger
+}
+
+// NewSealedTransport wraps transport with message sealing. metrics can be nil.
+func NewSealedTransport(transport Transport, keyring *Keyring, metrics *SecurityMetrics) *SealedTransport {
+	if transport == nil {
+		panic("transport is nil")
+	}
+	if keyring == nil {
+		panic("keyring is nil")
+	}
+	if metrics == nil {
+		metrics = &SecurityMetrics{}
+	}
+	return &SealedTransport{
+		Transport: transport,
+		keyring:   keyring,
+		metrics:   metrics,
+		log:       sladder.DefaultLogger,
+	}
+}
+
+// SetLogger sets logger to report sealing failures.
+func (t *SealedTransport) SetLogger(log sladder.Logger) {
+	if log != nil {
+		t.log = log
+	}
+}
+
+// Keyring returns keyring in use.
+func (t *SealedTransport) Keyring() *Keyring { return t.keyring }
+
+// Send seals message and sends it.
+func (t *SealedTransport) Send(names []string, raw []byte) {
+	sealed, err := t.keyring.Seal(raw)
+	if err != nil {
+		t.metrics.ApplyIncrement(&SecurityMetricIncrement{SealFailure: 1})
+		t.log.Warnf("failed to seal outgoing message. drop it. (err = \"%v\") {node = %v}", err, names)
+		return
+	}
+	t.metrics.ApplyIncrement(&SecurityMetricIncrement{Sealed: 1})
+	t.Transport.Send(names, sealed)
+}
+
+// Receive receives authenticated message. It returns nil when the context is done.
+func (t *SealedTransport) Receive(ctx context.Context) ([]string, []byte) {
+	for {
+		from, raw := t.Transport.Receive(ctx)
+		if len(from) < 1 || raw == nil {
+			return nil, nil
+		}
+		plain, err := t.keyring.Open(raw)
+		if err != nil {
+			inc := &SecurityMetricIncrement{}
+			if err == ErrMessageAuthentication {
+				inc.Unauthenticated++
+			} else {
+				inc.Malformed++
+			}
+			t.metrics.ApplyIncrement(inc)
+			continue
+		}
+		t.metrics.ApplyIncrement(&SecurityMetricIncrement{Opened: 1})
+		return from, plain
+	}
+}
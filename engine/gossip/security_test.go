@@ -0,0 +1,190 @@
+package gossip
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	"github.com/crossmesh/sladder/engine/gossip/pb"
+	"github.com/crossmesh/sladder/engine/gossip/simnet"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+func marshalTestGossipMessage(t *testing.T, ty pb.GossipMessage_Type, body protoiface.MessageV1) []byte {
+	msg := &pb.GossipMessage{Type: ty}
+	var err error
+	if msg.Body, err = ptypes.MarshalAny(body); !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	raw, err := proto.Marshal(msg)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return raw
+}
+
+func TestKeyring(t *testing.T) {
+	k1, k2, k3 := bytes.Repeat([]byte{1}, 16), bytes.Repeat([]byte{2}, 24), bytes.Repeat([]byte{3}, 32)
+
+	t.Run("manage", func(t *testing.T) {
+		_, err := NewKeyring([]byte("short"))
+		assert.Equal(t, ErrInvalidKeySize, err)
+
+		k, err := NewKeyring(k1, k2)
+		assert.NoError(t, err)
+		assert.Equal(t, k1, k.PrimaryKey())
+		assert.Equal(t, [][]byte{k1, k2}, k.Keys())
+
+		assert.Equal(t, ErrInvalidKeySize, k.AddKey(k3[:31]))
+		assert.NoError(t, k.AddKey(k3))
+		assert.NoError(t, k.AddKey(k3))
+		assert.Equal(t, 3, len(k.Keys()))
+
+		assert.Equal(t, ErrKeyNotFound, k.UseKey(bytes.Repeat([]byte{4}, 16)))
+		assert.NoError(t, k.UseKey(k2))
+		assert.Equal(t, k2, k.PrimaryKey())
+
+		assert.Equal(t, ErrRemovePrimaryKey, k.RemoveKey(k2))
+		assert.Equal(t, ErrKeyNotFound, k.RemoveKey(bytes.Repeat([]byte{4}, 16)))
+		assert.NoError(t, k.RemoveKey(k1))
+		assert.Equal(t, [][]byte{k2, k3}, k.Keys())
+	})
+
+	t.Run("seal_open", func(t *testing.T) {
+		raw := marshalTestGossipMessage(t, pb.GossipMessage_Sync, &pb.Sync{Id: 1})
+
+		old, err := NewKeyring(k1)
+		assert.NoError(t, err)
+		rotating, err := NewKeyring(k1, k2)
+		assert.NoError(t, err)
+		rotated, err := NewKeyring(k2)
+		assert.NoError(t, err)
+
+		sealed, err := old.Seal(raw)
+		assert.NoError(t, err)
+		assert.False(t, bytes.Contains(sealed, raw))
+		var envelope pb.Sealed
+		assert.NoError(t, proto.Unmarshal(sealed, &envelope))
+		assert.Equal(t, pb.GossipMessage_Sync, envelope.Type)
+
+		// rotating node accepts both keys.
+		plain, err := rotating.Open(sealed)
+		assert.NoError(t, err)
+		assert.Equal(t, raw, plain)
+		_, err = rotated.Open(sealed)
+		assert.Equal(t, ErrMessageAuthentication, err)
+
+		assert.NoError(t, rotating.UseKey(k2))
+		sealed, err = rotating.Seal(raw)
+		assert.NoError(t, err)
+		plain, err = rotated.Open(sealed)
+		assert.NoError(t, err)
+		assert.Equal(t, raw, plain)
+		_, err = old.Open(sealed)
+		assert.Equal(t, ErrMessageAuthentication, err)
+
+		// forged type.
+		assert.NoError(t, proto.Unmarshal(sealed, &envelope))
+		envelope.Type = pb.GossipMessage_Ping
+		forged, err := proto.Marshal(&envelope)
+		assert.NoError(t, err)
+		_, err = rotated.Open(forged)
+		assert.Equal(t, ErrMessageAuthentication, err)
+
+		// tampered payload.
+		assert.NoError(t, proto.Unmarshal(sealed, &envelope))
+		envelope.Payload[len(envelope.Payload)-1] ^= 0xFF
+		tampered, err := proto.Marshal(&envelope)
+		assert.NoError(t, err)
+		_, err = rotated.Open(tampered)
+		assert.Equal(t, ErrMessageAuthentication, err)
+
+		// truncated payload.
+		envelope.Payload = envelope.Payload[:4]
+		truncated, err := proto.Marshal(&envelope)
+		assert.NoError(t, err)
+		_, err = rotated.Open(truncated)
+		assert.Equal(t, ErrMessageAuthentication, err)
+
+		_, err = rotated.Open([]byte{0xFF})
+		assert.Error(t, err)
+		assert.NotEqual(t, ErrMessageAuthentication, err)
+	})
+}
+
+func TestSealedTransport(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 16)
+	keyring, err := NewKeyring(key)
+	assert.NoError(t, err)
+
+	t.Run("new", func(t *testing.T) {
+		assert.Panics(t, func() { NewSealedTransport(nil, keyring, nil) })
+		tp := simnet.New().Transport("default")
+		assert.Panics(t, func() { NewSealedTransport(tp, nil, nil) })
+
+		e := New(tp, WithKeyring(keyring)).(*EngineInstance)
+		sealed, isSealed := e.transport.(*SealedTransport)
+		assert.True(t, isSealed)
+		assert.Equal(t, keyring, sealed.Keyring())
+		assert.Equal(t, &e.Metrics.Security, sealed.metrics)
+	})
+
+	t.Run("seal_failure", func(t *testing.T) {
+		log := &sladder.MockLogger{}
+		log.On("Warnf", mock.Anything, mock.Anything, mock.Anything).Return()
+
+		e := New(simnet.New().Transport("default"), WithKeyring(keyring), WithLogger(log)).(*EngineInstance)
+		sealed := e.transport.(*SealedTransport)
+		sealed.Send([]string{"peer"}, []byte{0xff}) // malformed message.
+		log.AssertNumberOfCalls(t, "Warnf", 1)
+
+		e.Metrics.Security.lock.Lock()
+		defer e.Metrics.Security.lock.Unlock()
+		assert.Equal(t, uint64(1), e.Metrics.Security.SealFailure)
+		assert.Equal(t, uint64(0), e.Metrics.Security.Sealed)
+	})
+
+	t.Run("engine", func(t *testing.T) {
+		god, ctl, err := newHealthyClusterGod(t, "tst", 1, 5, []sladder.EngineOption{WithKeyring(keyring)}, nil)
+		assert.NoError(t, err)
+		assert.True(t, god.AllViewpointConsist(true, true))
+
+		vps := god.VPList()
+		vp := vps[0]
+		vp.engine.Metrics.Security.lock.Lock()
+		m := vp.engine.Metrics.Security.SecurityMetricIncrement
+		vp.engine.Metrics.Security.lock.Unlock()
+		assert.Greater(t, m.Sealed, uint64(0))
+		assert.Greater(t, m.Opened, uint64(0))
+		assert.Equal(t, uint64(0), m.Unauthenticated)
+		assert.Equal(t, uint64(0), m.Malformed)
+
+		// inject messages.
+		rogueKeyring, err := NewKeyring(bytes.Repeat([]byte{2}, 16))
+		assert.NoError(t, err)
+		rogue := ctl.Transport("rogue")
+		raw := marshalTestGossipMessage(t, pb.GossipMessage_Sync, &pb.Sync{Id: 1, Type: pb.Sync_Push})
+		sealed, err := rogueKeyring.Seal(raw)
+		assert.NoError(t, err)
+		rogue.Send(vp.cv.Self().Names(), sealed)
+		rogue.Send(vp.cv.Self().Names(), raw[:len(raw)-1])
+
+		deadline := time.Now().Add(time.Second * 5)
+		for time.Now().Before(deadline) {
+			vp.engine.Metrics.Security.lock.Lock()
+			m = vp.engine.Metrics.Security.SecurityMetricIncrement
+			vp.engine.Metrics.Security.lock.Unlock()
+			if m.Unauthenticated > 0 && m.Malformed > 0 {
+				break
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		assert.Equal(t, uint64(1), m.Unauthenticated)
+		assert.Equal(t, uint64(1), m.Malformed)
+	})
+}
@@ -0,0 +1,86 @@
+package simnet
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultReorderDelay = time.Millisecond * 10
+)
+
+// Latency samples message propagation delay.
+type Latency interface {
+	Sample(r *rand.Rand) time.Duration
+}
+
+// ConstantLatency delays every message for fixed duration.
+type ConstantLatency time.Duration
+
+// Sample returns the constant delay.
+func (l ConstantLatency) Sample(r *rand.Rand) time.Duration { return time.Duration(l) }
+
+// UniformLatency delays message for a duration uniformly distributed in [Min, Max).
+type UniformLatency struct {
+	Min, Max time.Duration
+}
+
+// Sample returns random delay.
+func (l UniformLatency) Sample(r *rand.Rand) time.Duration {
+	if l.Max <= l.Min {
+		return l.Min
+	}
+	return l.Min + time.Duration(r.Int63n(int64(l.Max-l.Min)))
+}
+
+// NormalLatency delays message for a normally distributed duration. Negative samples are clamped to zero.
+type NormalLatency struct {
+	Mean, StdDev time.Duration
+}
+
+// Sample returns random delay.
+func (l NormalLatency) Sample(r *rand.Rand) time.Duration {
+	d := time.Duration(r.NormFloat64()*float64(l.StdDev)) + l.Mean
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// ExponentialLatency delays message for Min plus an exponentially distributed duration,
+// which simulates long-tail delay.
+type ExponentialLatency struct {
+	Min, Mean time.Duration
+}
+
+// Sample returns random delay.
+func (l ExponentialLatency) Sample(r *rand.Rand) time.Duration {
+	tail := r.ExpFloat64() * float64(l.Mean)
+	if tail > math.MaxInt64/2 {
+		tail = math.MaxInt64 / 2
+	}
+	return l.Min + time.Duration(tail)
+}
+
+// Faults describes faults injected to a link.
+type Faults struct {
+	// Loss is the probability that a message is dropped.
+	Loss float64
+	// Duplicate is the probability that a message is delivered twice.
+	Duplicate float64
+	// Reorder is the probability that a message is held for ReorderDelay, so that later messages overtake it.
+	Reorder      float64
+	ReorderDelay time.Duration
+	// Latency is the delay distribution of delivery. nil means no delay.
+	Latency Latency
+	// Bandwidth limits bytes per second through the link. 0 means unlimited.
+	Bandwidth int64
+}
+
+func (f *Faults) getReorderDelay() time.Duration {
+	if f.ReorderDelay > 0 {
+		return f.ReorderDelay
+	}
+	return defaultReorderDelay
+}
@@ -0,0 +1,680 @@
+package simnet
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultMaxConcurrentMessage is default limitation of message group size.
+	DefaultMaxConcurrentMessage = 4
+)
+
+type message struct {
+	msg  []byte
+	from []string
+	next *message
+
+	gid uint32
+}
+
+type messageGroupMeta struct {
+	numOfMsg int32
+	id       uint32
+	next     *messageGroupMeta
+}
+
+type jam struct {
+	from string
+	to   string
+}
+
+type link struct {
+	from, to *Transport
+}
+
+type linkFaultsKey struct {
+	from, to string
+}
+
+// Metrics contains statistics of simulated network.
+type Metrics struct {
+	Sent       uint64
+	Delivered  uint64
+	Dropped    uint64
+	Duplicated uint64
+}
+
+// Network simulates a network connecting in-process transports.
+//
+// Messages may be jammed by links and partitions, or be affected by faults (loss, duplication,
+// reordering, latency and bandwidth caps) configured on network or links.
+type Network struct {
+	lock sync.RWMutex
+
+	condNewMessageGroupRelease *sync.Cond
+	concurrencyLeases          int32
+	messageGroup               uint32
+	messageGroupMetas          *messageGroupMeta
+	messageGroupMetasTail      *messageGroupMeta
+
+	transports map[string]*Transport
+
+	jams       map[jam]struct{}
+	partitions map[*Partition]struct{}
+
+	faults        *Faults
+	linkFaults    map[linkFaultsKey]*Faults
+	linkBusyUntil map[link]time.Time
+	rand          *rand.Rand
+
+	metrics Metrics
+
+	// MaxConcurrentMessage limits number of messages in a message group.
+	// Message groups are released by receivers in order, which bounds the disorder of message processing
+	// among transports. 0 disables the limitation.
+	MaxConcurrentMessage int32
+}
+
+// New creates simulated network.
+func New() (n *Network) {
+	n = &Network{
+		transports:           make(map[string]*Transport),
+		MaxConcurrentMessage: DefaultMaxConcurrentMessage,
+		jams:                 make(map[jam]struct{}),
+		partitions:           make(map[*Partition]struct{}),
+		linkFaults:           make(map[linkFaultsKey]*Faults),
+		linkBusyUntil:        make(map[link]time.Time),
+		rand:                 rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	n.condNewMessageGroupRelease = sync.NewCond(&n.lock)
+	return
+}
+
+// Seed resets random source of the network, to make fault injection reproducible.
+func (n *Network) Seed(seed int64) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.rand = rand.New(rand.NewSource(seed))
+}
+
+// Metrics returns statistics of the network.
+func (n *Network) Metrics() Metrics {
+	return Metrics{
+		Sent:       atomic.LoadUint64(&n.metrics.Sent),
+		Delivered:  atomic.LoadUint64(&n.metrics.Delivered),
+		Dropped:    atomic.LoadUint64(&n.metrics.Dropped),
+		Duplicated: atomic.LoadUint64(&n.metrics.Duplicated),
+	}
+}
+
+// SetFaults sets faults for all links without link-specific faults. nil clears faults.
+func (n *Network) SetFaults(f *Faults) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.faults = f
+}
+
+// SetLinkFaults sets faults for the directional link from one node to another. nil clears faults.
+func (n *Network) SetLinkFaults(from, to string, f *Faults) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	key := linkFaultsKey{from: from, to: to}
+	if f == nil {
+		delete(n.linkFaults, key)
+	} else {
+		n.linkFaults[key] = f
+	}
+}
+
+func (n *Network) getFaults(from, to []string) *Faults {
+	for _, from := range from {
+		for _, to := range to {
+			if f, exists := n.linkFaults[linkFaultsKey{from: from, to: to}]; exists {
+				return f
+			}
+		}
+	}
+	return n.faults
+}
+
+func (n *Network) networkJam(from, to string) {
+	n.jams[jam{
+		from: from, to: to,
+	}] = struct{}{}
+}
+
+// NetworkJam drops all messages from one node to another.
+func (n *Network) NetworkJam(from, to string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.networkJam(from, to)
+}
+
+func (n *Network) clearNetworkJam(from, to string) {
+	delete(n.jams, jam{
+		from: from, to: to,
+	})
+}
+
+// ClearNetworkJam removes jam from one node to another.
+func (n *Network) ClearNetworkJam(from, to string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.clearNetworkJam(from, to)
+}
+
+// NetworkOutJam drops all messages sent by node.
+func (n *Network) NetworkOutJam(names []string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, name := range names {
+		n.networkJam(name, "")
+	}
+}
+
+// ClearNetworkOutJam removes jam set by NetworkOutJam.
+func (n *Network) ClearNetworkOutJam(names []string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, name := range names {
+		n.clearNetworkJam(name, "")
+	}
+}
+
+// NetworkInJam drops all messages sent to node.
+func (n *Network) NetworkInJam(names []string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, name := range names {
+		n.networkJam("", name)
+	}
+}
+
+// ClearNetworkInJam removes jam set by NetworkInJam.
+func (n *Network) ClearNetworkInJam(names []string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, name := range names {
+		n.clearNetworkJam("", name)
+	}
+}
+
+func (n *Network) addPartition(directed bool, groups ...[][]string) (part *Partition, err error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if len(groups) < 1 {
+		return nil, nil
+	}
+
+	getPart := func() *Partition {
+		if part == nil {
+			part = &Partition{
+				indices:  make(map[string]*partitionIndex),
+				directed: directed,
+			}
+		}
+		return part
+	}
+
+	for _, group := range groups {
+		nIdx, eliIdx := 0, 0
+		for ; nIdx < len(group); nIdx++ {
+			node := group[nIdx]
+			if len(node) < 1 {
+				continue
+			}
+			if eliIdx != nIdx {
+				group[eliIdx] = group[nIdx]
+			}
+			eliIdx++
+		}
+		group = group[:eliIdx]
+		if len(group) < 1 {
+			if directed {
+				return nil, nil
+			}
+			continue
+		}
+		part := getPart()
+		part.groups = append(part.groups, group)
+	}
+	if part != nil {
+		if err = part.updateIndices(); err != nil {
+			return nil, err
+		}
+		n.partitions[part] = struct{}{}
+	}
+	return
+}
+
+// NetworkPartition splits nodes into groups. Messages between different groups will be dropped.
+func (n *Network) NetworkPartition(groups ...[][]string) (part *Partition, err error) {
+	return n.addPartition(false, groups...)
+}
+
+// NetworkOneWayPartition drops messages from nodes in group "from" to nodes in group "to",
+// while messages of reverse direction are still delivered.
+func (n *Network) NetworkOneWayPartition(from, to [][]string) (part *Partition, err error) {
+	return n.addPartition(true, from, to)
+}
+
+// RemovePartition removes network partition.
+func (n *Network) RemovePartition(part *Partition) {
+	if part == nil {
+		return
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	delete(n.partitions, part)
+}
+
+// JamDropMessage reports whether messages from one node to another will be dropped by jams or partitions.
+func (n *Network) JamDropMessage(from, to []string) bool {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.jamDropMessage(from, to)
+}
+
+func (n *Network) jamDropMessage(from, to []string) bool {
+	for _, from := range from {
+		if _, drop := n.jams[jam{
+			from: from, to: "",
+		}]; drop {
+			return true
+		}
+	}
+
+	for _, to := range to {
+		if _, drop := n.jams[jam{
+			from: "", to: to,
+		}]; drop {
+			return true
+		}
+	}
+
+	for _, from := range from {
+		for _, to := range to {
+			if _, drop := n.jams[jam{
+				from: from, to: to,
+			}]; drop {
+				return true
+			}
+		}
+	}
+
+	// check network partition.
+	for rule := range n.partitions {
+		if rule.Jam(from, to) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *Network) getTransport(create bool, names ...string) (t *Transport) {
+	for _, name := range names {
+		nt, hasTransport := n.transports[name]
+		if hasTransport && nt != nil {
+			t = nt
+			break
+		}
+	}
+	if !create {
+		return
+	}
+
+	if t == nil {
+		t = &Transport{
+			names:   names,
+			n:       n,
+			watcher: make(map[chan struct{}]struct{}),
+		}
+		for _, name := range names {
+			n.transports[name] = t
+		}
+
+	} else {
+		for _, name := range t.names {
+			if nt, _ := n.transports[name]; nt != t {
+				delete(n.transports, name)
+			}
+		}
+		for _, name := range names {
+			n.transports[name] = t
+		}
+		t.names = names
+
+	}
+
+	return t
+}
+
+// RemoveTransportTarget detaches node from network. Pending messages of the node will be dropped.
+func (n *Network) RemoveTransportTarget(names ...string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	t := n.getTransport(false, names...)
+	if t == nil {
+		return
+	}
+	for _, name := range names {
+		delete(n.transports, name)
+	}
+	go t.FlushQueue()
+}
+
+// Transport attaches node to network and returns its transport.
+// Existing transport will be returned if any of names is already attached.
+func (n *Network) Transport(names ...string) *Transport {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.getTransport(true, names...)
+}
+
+func (n *Network) appendMessageGroup(numOfMsg int32, gid uint32) {
+	new := &messageGroupMeta{
+		numOfMsg: numOfMsg,
+		id:       gid,
+		next:     nil,
+	}
+	if n.messageGroupMetas == nil {
+		n.messageGroupMetas = new
+		n.condNewMessageGroupRelease.Broadcast()
+	} else {
+		n.messageGroupMetasTail.next = new
+	}
+	n.messageGroupMetasTail = new
+}
+
+func (n *Network) dropMessageGroup() {
+	if n.messageGroupMetas == nil {
+		return
+	}
+	n.messageGroupMetas = n.messageGroupMetas.next
+	if n.messageGroupMetas == nil {
+		n.messageGroupMetasTail = nil
+	} else {
+		n.condNewMessageGroupRelease.Broadcast()
+	}
+}
+
+func (n *Network) traceNewMessage() (gid uint32) {
+	if n.MaxConcurrentMessage > 0 {
+		for {
+			if n.concurrencyLeases > 0 {
+				n.concurrencyLeases--
+				return n.messageGroup
+			}
+			n.concurrencyLeases = n.rand.Int31n(n.MaxConcurrentMessage) + 1
+			n.messageGroup++
+			n.appendMessageGroup(n.concurrencyLeases, n.messageGroup)
+		}
+	}
+	return
+}
+
+func (n *Network) releaseMessage(gid uint32) {
+	for {
+		if n.messageGroupMetas == nil || n.messageGroupMetas.id < gid {
+			n.condNewMessageGroupRelease.Wait()
+			continue
+		}
+
+		n.messageGroupMetas.numOfMsg--
+		if n.messageGroupMetas.numOfMsg < 1 {
+			n.dropMessageGroup()
+		}
+		break
+	}
+}
+
+func (n *Network) deliveryDelay(l link, f *Faults, size int) (delay time.Duration) {
+	if f.Latency != nil {
+		delay += f.Latency.Sample(n.rand)
+	}
+	if f.Reorder > 0 && n.rand.Float64() < f.Reorder {
+		delay += f.getReorderDelay()
+	}
+	if f.Bandwidth > 0 {
+		now := time.Now()
+		start := n.linkBusyUntil[l]
+		if start.Before(now) {
+			start = now
+		}
+		end := start.Add(time.Duration(int64(size) * int64(time.Second) / f.Bandwidth))
+		n.linkBusyUntil[l] = end
+		delay += end.Sub(now)
+	}
+	return
+}
+
+// Partition splits network nodes into groups.
+type Partition struct {
+	groups   [][][]string
+	indices  map[string]*partitionIndex
+	directed bool
+}
+
+type partitionIndex struct {
+	group  int
+	nodeID int
+}
+
+func (p *Partition) updateIndices() error {
+	p.indices = make(map[string]*partitionIndex)
+	for gid, group := range p.groups {
+		for nid, node := range group {
+			for _, name := range node {
+				if part, exists := p.indices[name]; exists {
+					return fmt.Errorf("group overlapped: %v is already in group %v", name, p.groups[part.group])
+				}
+				p.indices[name] = &partitionIndex{
+					group: gid, nodeID: nid,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Jam reports whether messages from one node to another are dropped by the partition.
+func (p *Partition) Jam(from, to []string) (exist bool) {
+	var gfrom, gto *partitionIndex
+
+	for _, from := range from {
+		for _, to := range to {
+			if gfrom, exist = p.indices[from]; !exist {
+				continue
+			}
+			if gto, exist = p.indices[to]; !exist {
+				continue
+			}
+			if gfrom.group == gto.group {
+				continue
+			}
+			if p.directed && gfrom.group != 0 {
+				continue
+			}
+			return true
+		}
+	}
+
+	return false
+}
+
+// Transport is the endpoint of node attached to simulated network.
+// It implements gossip transport.
+type Transport struct {
+	lock sync.Mutex
+
+	names []string
+	n     *Network
+
+	mqh     *message
+	mqt     *message
+	watcher map[chan struct{}]struct{}
+}
+
+// Names returns names of node.
+func (t *Transport) Names() []string {
+	t.n.lock.RLock()
+	defer t.n.lock.RUnlock()
+	return t.names
+}
+
+// Send sends message to node.
+func (t *Transport) Send(names []string, buf []byte) {
+	n := t.n
+	atomic.AddUint64(&n.metrics.Sent, 1)
+
+	n.lock.Lock()
+	from := t.names
+	target := n.getTransport(false, names...)
+	if target == nil || n.jamDropMessage(from, names) {
+		n.lock.Unlock()
+		atomic.AddUint64(&n.metrics.Dropped, 1)
+		return
+	}
+	f := n.getFaults(from, names)
+	if f == nil {
+		n.lock.Unlock()
+		target.enqueue(from, buf)
+		return
+	}
+
+	if f.Loss > 0 && n.rand.Float64() < f.Loss {
+		n.lock.Unlock()
+		atomic.AddUint64(&n.metrics.Dropped, 1)
+		return
+	}
+	copies := 1
+	if f.Duplicate > 0 && n.rand.Float64() < f.Duplicate {
+		copies++
+		atomic.AddUint64(&n.metrics.Duplicated, 1)
+	}
+	l, delays := link{from: t, to: target}, make([]time.Duration, 0, copies)
+	for i := 0; i < copies; i++ {
+		delays = append(delays, n.deliveryDelay(l, f, len(buf)))
+	}
+	n.lock.Unlock()
+
+	for _, delay := range delays {
+		if delay <= 0 {
+			target.enqueue(from, buf)
+			continue
+		}
+		time.AfterFunc(delay, func() {
+			n.lock.RLock()
+			attached := n.getTransport(false, names...) == target
+			n.lock.RUnlock()
+			if !attached { // target left during flight.
+				atomic.AddUint64(&n.metrics.Dropped, 1)
+				return
+			}
+			target.enqueue(from, buf)
+		})
+	}
+}
+
+func (t *Transport) enqueue(from []string, buf []byte) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.n.lock.Lock()
+	gid := t.n.traceNewMessage()
+	t.n.lock.Unlock()
+
+	m := &message{
+		msg:  buf,
+		from: from,
+		gid:  gid,
+	}
+
+	// enqueue
+	if t.mqh == nil {
+		t.mqh, t.mqt = m, m
+	} else {
+		t.mqt.next = m
+		t.mqt = t.mqt.next
+	}
+	atomic.AddUint64(&t.n.metrics.Delivered, 1)
+
+	// notify.
+	for wc := range t.watcher {
+		wc <- struct{}{}
+		delete(t.watcher, wc)
+	}
+}
+
+func (t *Transport) dequeueMessage() (from []string, buf []byte, gid uint32) {
+	if t.mqh != nil {
+		from, buf, gid = t.mqh.from, t.mqh.msg, t.mqh.gid
+		// dequeue.
+		t.mqh = t.mqh.next
+		if t.mqh == nil {
+			t.mqt = nil
+		}
+	}
+	return
+}
+
+// Receive receives message. It returns nil when the context is done.
+func (t *Transport) Receive(ctx context.Context) (from []string, buf []byte) {
+	var gid uint32
+
+	t.lock.Lock()
+
+	var wc chan struct{}
+
+	for {
+		if from, buf, gid = t.dequeueMessage(); buf != nil {
+			break
+		}
+		if wc == nil {
+			wc = make(chan struct{}, 1)
+		}
+		t.watcher[wc] = struct{}{}
+
+		t.lock.Unlock()
+		select {
+		case <-ctx.Done():
+			return
+		case <-wc:
+		}
+		t.lock.Lock()
+	}
+
+	t.lock.Unlock()
+
+	t.n.lock.Lock()
+	t.n.releaseMessage(gid)
+	t.n.lock.Unlock()
+
+	return
+}
+
+// FlushQueue drops all pending messages.
+func (t *Transport) FlushQueue() {
+	for {
+		t.lock.Lock()
+		_, buf, gid := t.dequeueMessage()
+		t.lock.Unlock()
+		if buf == nil {
+			break
+		}
+
+		t.n.lock.Lock()
+		t.n.releaseMessage(gid)
+		t.n.lock.Unlock()
+	}
+}
@@ -0,0 +1,194 @@
+package simnet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder/engine/gossip"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ gossip.Transport = (*Transport)(nil)
+
+func receiveWithTimeout(t *Transport, timeout time.Duration) ([]string, []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return t.Receive(ctx)
+}
+
+func TestNetwork(t *testing.T) {
+	t.Run("send_receive", func(t *testing.T) {
+		n := New()
+		t1, t2 := n.Transport("n1", "n1-alias"), n.Transport("n2")
+		assert.Equal(t, []string{"n1", "n1-alias"}, t1.Names())
+		assert.Equal(t, t1, n.Transport("n1", "n1-alias"))
+
+		t1.Send([]string{"n2"}, []byte("hello"))
+		from, buf := receiveWithTimeout(t2, time.Second)
+		assert.Equal(t, []string{"n1", "n1-alias"}, from)
+		assert.Equal(t, []byte("hello"), buf)
+
+		// unknown target.
+		t1.Send([]string{"n3"}, []byte("hello"))
+		from, buf = receiveWithTimeout(t2, time.Millisecond*50)
+		assert.Nil(t, from)
+		assert.Nil(t, buf)
+
+		m := n.Metrics()
+		assert.Equal(t, uint64(2), m.Sent)
+		assert.Equal(t, uint64(1), m.Delivered)
+		assert.Equal(t, uint64(1), m.Dropped)
+
+		n.RemoveTransportTarget("n2")
+		t1.Send([]string{"n2"}, []byte("hello"))
+		assert.Equal(t, uint64(2), n.Metrics().Dropped)
+	})
+
+	t.Run("jam", func(t *testing.T) {
+		n := New()
+		t1, t2 := n.Transport("n1"), n.Transport("n2")
+
+		n.NetworkJam("n1", "n2")
+		assert.True(t, n.JamDropMessage([]string{"n1"}, []string{"n2"}))
+		assert.False(t, n.JamDropMessage([]string{"n2"}, []string{"n1"}))
+		t1.Send([]string{"n2"}, []byte("1"))
+		t2.Send([]string{"n1"}, []byte("2"))
+		_, buf := receiveWithTimeout(t2, time.Millisecond*50)
+		assert.Nil(t, buf)
+		_, buf = receiveWithTimeout(t1, time.Second)
+		assert.Equal(t, []byte("2"), buf)
+		n.ClearNetworkJam("n1", "n2")
+		assert.False(t, n.JamDropMessage([]string{"n1"}, []string{"n2"}))
+
+		n.NetworkInJam([]string{"n2"})
+		assert.True(t, n.JamDropMessage([]string{"n1"}, []string{"n2"}))
+		n.ClearNetworkInJam([]string{"n2"})
+		n.NetworkOutJam([]string{"n2"})
+		assert.True(t, n.JamDropMessage([]string{"n2"}, []string{"n1"}))
+		assert.False(t, n.JamDropMessage([]string{"n1"}, []string{"n2"}))
+		n.ClearNetworkOutJam([]string{"n2"})
+		assert.False(t, n.JamDropMessage([]string{"n2"}, []string{"n1"}))
+	})
+
+	t.Run("partition", func(t *testing.T) {
+		n := New()
+		part, err := n.NetworkPartition([][]string{{"n1"}, {"n2"}}, [][]string{{"n3"}, {}})
+		assert.NoError(t, err)
+		assert.NotNil(t, part)
+		assert.False(t, n.JamDropMessage([]string{"n1"}, []string{"n2"}))
+		assert.True(t, n.JamDropMessage([]string{"n1"}, []string{"n3"}))
+		assert.True(t, n.JamDropMessage([]string{"n3"}, []string{"n2"}))
+		assert.False(t, n.JamDropMessage([]string{"n1"}, []string{"n4"}))
+		n.RemovePartition(part)
+		assert.False(t, n.JamDropMessage([]string{"n1"}, []string{"n3"}))
+
+		_, err = n.NetworkPartition([][]string{{"n1"}}, [][]string{{"n1"}})
+		assert.Error(t, err)
+
+		part, err = n.NetworkOneWayPartition([][]string{{"n1"}, {"n2"}}, [][]string{{"n3"}})
+		assert.NoError(t, err)
+		assert.True(t, n.JamDropMessage([]string{"n1"}, []string{"n3"}))
+		assert.True(t, n.JamDropMessage([]string{"n2"}, []string{"n3"}))
+		assert.False(t, n.JamDropMessage([]string{"n3"}, []string{"n1"}))
+		assert.False(t, n.JamDropMessage([]string{"n1"}, []string{"n2"}))
+		n.RemovePartition(part)
+		assert.False(t, n.JamDropMessage([]string{"n1"}, []string{"n3"}))
+	})
+
+	t.Run("loss", func(t *testing.T) {
+		n := New()
+		t1, t2 := n.Transport("n1"), n.Transport("n2")
+		n.SetLinkFaults("n1", "n2", &Faults{Loss: 1})
+		for i := 0; i < 10; i++ {
+			t1.Send([]string{"n2"}, []byte("1"))
+		}
+		_, buf := receiveWithTimeout(t2, time.Millisecond*50)
+		assert.Nil(t, buf)
+		assert.Equal(t, uint64(10), n.Metrics().Dropped)
+
+		// reverse link is not affected.
+		t2.Send([]string{"n1"}, []byte("2"))
+		_, buf = receiveWithTimeout(t1, time.Second)
+		assert.Equal(t, []byte("2"), buf)
+
+		n.SetLinkFaults("n1", "n2", nil)
+		n.Seed(1)
+		n.SetFaults(&Faults{Loss: 0.5})
+		for i := 0; i < 1000; i++ {
+			t1.Send([]string{"n2"}, []byte("1"))
+		}
+		dropped := n.Metrics().Dropped - 10
+		assert.True(t, dropped > 400 && dropped < 600, "dropped %v of 1000 messages", dropped)
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		n := New()
+		t1, t2 := n.Transport("n1"), n.Transport("n2")
+		n.SetFaults(&Faults{Duplicate: 1})
+		t1.Send([]string{"n2"}, []byte("1"))
+		for i := 0; i < 2; i++ {
+			_, buf := receiveWithTimeout(t2, time.Second)
+			assert.Equal(t, []byte("1"), buf)
+		}
+		_, buf := receiveWithTimeout(t2, time.Millisecond*50)
+		assert.Nil(t, buf)
+		assert.Equal(t, uint64(1), n.Metrics().Duplicated)
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		n := New()
+		t1, t2 := n.Transport("n1"), n.Transport("n2")
+		n.SetFaults(&Faults{Latency: ConstantLatency(time.Millisecond * 100)})
+		start := time.Now()
+		t1.Send([]string{"n2"}, []byte("1"))
+		_, buf := receiveWithTimeout(t2, time.Second)
+		assert.Equal(t, []byte("1"), buf)
+		assert.True(t, time.Since(start) >= time.Millisecond*100)
+
+		// message in flight is dropped when target leaves.
+		t1.Send([]string{"n2"}, []byte("1"))
+		n.RemoveTransportTarget("n2")
+		time.Sleep(time.Millisecond * 200)
+		assert.Equal(t, uint64(1), n.Metrics().Dropped)
+	})
+
+	t.Run("latency_distribution", func(t *testing.T) {
+		n := New()
+		for i := 0; i < 100; i++ {
+			d := UniformLatency{Min: time.Millisecond, Max: time.Millisecond * 5}.Sample(n.rand)
+			assert.True(t, d >= time.Millisecond && d < time.Millisecond*5)
+			assert.True(t, NormalLatency{Mean: time.Millisecond, StdDev: time.Millisecond * 10}.Sample(n.rand) >= 0)
+			assert.True(t, ExponentialLatency{Min: time.Millisecond, Mean: time.Millisecond}.Sample(n.rand) >= time.Millisecond)
+		}
+		assert.Equal(t, time.Millisecond, UniformLatency{Min: time.Millisecond}.Sample(n.rand))
+	})
+
+	t.Run("reorder", func(t *testing.T) {
+		n := New()
+		t1, t2 := n.Transport("n1"), n.Transport("n2")
+		n.SetFaults(&Faults{Reorder: 1, ReorderDelay: time.Millisecond * 50})
+		t1.Send([]string{"n2"}, []byte("1"))
+		n.SetFaults(nil)
+		t1.Send([]string{"n2"}, []byte("2"))
+		_, buf := receiveWithTimeout(t2, time.Second)
+		assert.Equal(t, []byte("2"), buf)
+		_, buf = receiveWithTimeout(t2, time.Second)
+		assert.Equal(t, []byte("1"), buf)
+	})
+
+	t.Run("bandwidth", func(t *testing.T) {
+		n := New()
+		t1, t2 := n.Transport("n1"), n.Transport("n2")
+		n.SetLinkFaults("n1", "n2", &Faults{Bandwidth: 1000})
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			t1.Send([]string{"n2"}, make([]byte, 100))
+		}
+		for i := 0; i < 3; i++ {
+			_, buf := receiveWithTimeout(t2, time.Second)
+			assert.Equal(t, 100, len(buf))
+		}
+		assert.True(t, time.Since(start) >= time.Millisecond*300)
+	})
+}
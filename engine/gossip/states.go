@@ -40,10 +40,21 @@ func (s SWIMState) String() string {
 
 // SWIMTag represents node gossip tag.
 type SWIMTag struct {
-	Version   uint32    `json:"v,omitempty"`
-	State     SWIMState `json:"s,omitempty"`
-	Region    string    `json:"r,omitempty"`
-	EntryList []string  `json:"l,omitempty"`
+	Version    uint32    `json:"v,omitempty"`
+	State      SWIMState `json:"s,omitempty"`
+	Region     string    `json:"r,omitempty"`
+	EntryList  []string  `json:"l,omitempty"`
+	Generation uint64    `json:"g,omitempty"` // boot generation. a restarted node has a larger one.
+}
+
+// Succeeds checks whether the tag is newer than another one of the same node.
+// Tags of a later generation succeed ones of former generations regardless of versions.
+// Generations are compared only when both tags have one.
+func (t *SWIMTag) Succeeds(o *SWIMTag) bool {
+	if t.Generation > 0 && o.Generation > 0 && t.Generation != o.Generation {
+		return t.Generation > o.Generation
+	}
+	return t.Version > o.Version
 }
 
 // Encode serializes SWIMTags.
@@ -91,6 +102,15 @@ func (c *SWIMTagValidator) Sync(entry *sladder.KeyValue, remote *sladder.KeyValu
 		return true, nil
 	}
 
+	// extended SWIM rule: accept tag of restarted node, and reject one of zombie.
+	if remoteTag.Generation > 0 && localTag.Generation > 0 && remoteTag.Generation != localTag.Generation {
+		if remoteTag.Generation > localTag.Generation {
+			entry.Value = remote.Value
+			return true, nil
+		}
+		return false, nil
+	}
+
 	// SWIM rule 1: accept newer tag version.
 	if remoteTag.Version > localTag.Version {
 		entry.Value = remote.Value
@@ -186,6 +206,16 @@ func (t *SWIMTagTxn) State() SWIMState { return t.tag.State }
 // Version returns current SWIM tag version.
 func (t *SWIMTagTxn) Version() uint32 { return t.tag.Version }
 
+// Generation returns boot generation of tag.
+func (t *SWIMTagTxn) Generation() uint64 { return t.tag.Generation }
+
+// SetGeneration updates boot generation.
+func (t *SWIMTagTxn) SetGeneration(g uint64) {
+	if t.tag.Generation != g {
+		t.tag.Generation, t.changed = g, true
+	}
+}
+
 // AddToEntryList add valid entries to entry list.
 func (t *SWIMTagTxn) AddToEntryList(keys ...string) {
 	if len(keys) < 0 {
@@ -227,6 +257,15 @@ func (t *SWIMTagTxn) BumpVersion() uint32 {
 	return t.tag.Version
 }
 
+// BumpVersionAbove advances tag version to be larger than v.
+func (t *SWIMTagTxn) BumpVersionAbove(v uint32) uint32 {
+	if t.tag.Version <= v {
+		t.tag.Version = v + 1
+		t.changed = true
+	}
+	return t.tag.Version
+}
+
 // ClaimDead set SWIM state to dead.
 func (t *SWIMTagTxn) ClaimDead() bool {
 	if t.tag.State == LEFT {
@@ -306,6 +345,11 @@ func (e *EngineInstance) ensureTransactionCommitIntegrity(t *sladder.Transaction
 		tag.SetRegion(e.region)
 		tag.ClaimAlive()
 		tag.BumpVersion()
+		// start above the last incarnation.
+		e.incarnationLock.Lock()
+		tag.SetGeneration(e.generation)
+		tag.BumpVersionAbove(e.lastIncarnation.Version)
+		e.incarnationLock.Unlock()
 	}
 
 	for idx := 0; idx < len(rcs); idx++ {
@@ -314,7 +358,8 @@ func (e *EngineInstance) ensureTransactionCommitIntegrity(t *sladder.Transaction
 			continue
 		}
 
-		if self == rc.Node && rc.Key != e.swimTagKey {
+		if self == rc.Node && rc.Key != e.swimTagKey &&
+			t.KeyFlags(rc.Key)&sladder.LocalEntry == 0 { // local entries are not synced.
 			switch {
 			case !rc.PastExists && rc.Exists:
 				addList = append(addList, rc.Key)
@@ -345,6 +390,10 @@ func (e *EngineInstance) ensureTransactionCommitIntegrity(t *sladder.Transaction
 }
 
 func (e *EngineInstance) onSelfSWIMStateChanged(self *sladder.Node, old, new *SWIMTag) {
+	if new.State == SUSPECTED && old.State != SUSPECTED {
+		// being suspected is a sign that this node may be slow.
+		e.adjustLocalHealth(1)
+	}
 	if new.State != ALIVE && e.quitAfter == 0 {
 		// clear false postive.
 		if err := e.cluster.Txn(func(t *sladder.Transaction) bool {
@@ -359,3 +408,30 @@ func (e *EngineInstance) onSelfSWIMStateChanged(self *sladder.Node, old, new *SW
 		}
 	}
 }
+
+// SWIMNodeState describes SWIM states of a node.
+type SWIMNodeState struct {
+	State      string `json:"state"`
+	Version    uint32 `json:"version"`
+	Region     string `json:"region,omitempty"`
+	Generation uint64 `json:"generation,omitempty"`
+}
+
+// DescribeNode describes SWIM states of node in JSON snapshot.
+func (e *EngineInstance) DescribeNode(t *sladder.Transaction, node *sladder.Node) interface{} {
+	if !t.KeyExists(node, e.swimTagKey) {
+		return nil
+	}
+	rtx, err := t.KV(node, e.swimTagKey)
+	if err != nil {
+		e.log.Warnf("cannot get SWIM tag to describe node. (err = \"%v\") {node = %v}", err, node.PrintableName())
+		return nil
+	}
+	tag := rtx.(*SWIMTagTxn)
+	return &SWIMNodeState{
+		State:      tag.State().String(),
+		Version:    tag.Version(),
+		Region:     tag.Region(),
+		Generation: tag.Generation(),
+	}
+}
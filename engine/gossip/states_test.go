@@ -584,3 +584,48 @@ func TestSWIMTag(t *testing.T) {
 	assert.Equal(t, "left", LEFT.String())
 	assert.Equal(t, "undefined", SWIMState(255).String())
 }
+
+func TestDescribeNode(t *testing.T) {
+	god, ctl, err := newHealthyClusterGod(t, "describe-tst", 1, 2, nil, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer god.Detach(ctl)
+
+	vp := god.VPList()[0]
+	assert.NoError(t, vp.cv.RegisterKey("key1", vp.engine.WrapVersionKVValidator(sladder.StringValidator{}), true, 0))
+	assert.NoError(t, vp.cv.Txn(func(tx *sladder.Transaction) bool {
+		rtx, err := tx.KV(vp.cv.Self(), "key1")
+		if !assert.NoError(t, err) {
+			return false
+		}
+		rtx.(*sladder.StringTxn).Set("v1")
+		return true
+	}))
+
+	s, err := vp.cv.JSONSnapshot()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 2, len(s.Nodes))
+	found := false
+	for _, node := range s.Nodes {
+		state, _ := node.State.(*SWIMNodeState)
+		if !assert.NotNil(t, state) {
+			continue
+		}
+		assert.Equal(t, "alive", state.State)
+		assert.NotZero(t, state.Generation)
+		if !node.Self {
+			continue
+		}
+		for _, entry := range node.Entries {
+			if entry.Key == "key1" {
+				found = true
+				assert.Equal(t, "v1", entry.Value)
+				assert.Equal(t, `{"o":"v1","v":1}`, entry.Raw)
+			}
+		}
+	}
+	assert.True(t, found)
+}
@@ -24,13 +24,14 @@ func (e *EngineInstance) _clearNodeFromSyncer(node *sladder.Node) {
 	delete(e.reversedExistence, node)
 }
 
-func (e *EngineInstance) newSyncClusterSnapshot(t *sladder.Transaction) (snap *proto.Cluster) {
+func (e *EngineInstance) newSyncClusterSnapshotWithNames(t *sladder.Transaction) (snap *proto.Cluster, names [][]string) {
 	snap = &proto.Cluster{}
 
 	readSnap := func(n *sladder.Node) {
 		nmsg := &proto.Node{}
 		t.ReadNodeSnapshot(n, nmsg)
 		snap.Nodes = append(snap.Nodes, nmsg)
+		names = append(names, t.Names(n))
 	}
 
 	t.RangeNode(func(n *sladder.Node) bool { // prepare snapshot.
@@ -48,6 +49,7 @@ func (e *EngineInstance) newSyncClusterSnapshot(t *sladder.Transaction) (snap *p
 	e._removeLeavingNode(removeIndics...)
 	for _, node := range e.leavingNodes {
 		snap.Nodes = append(snap.Nodes, node.snapshot)
+		names = append(names, node.names)
 	}
 	e.lock.Unlock()
 
@@ -61,8 +63,9 @@ func (e *EngineInstance) ClusterSync() {
 	}
 
 	var (
-		nodes [][]string
-		snap  *proto.Cluster
+		nodes       [][]string
+		pages       [][]*proto.Node
+		digestPages [][]*pb.NodeDigest
 	)
 
 	fanout := e.getGossipFanout()
@@ -90,7 +93,18 @@ func (e *EngineInstance) ClusterSync() {
 			return false
 		}, true, true)
 
-		snap = e.newSyncClusterSnapshot(t)
+		limit := e.maxSyncMessageSize
+		if e.syncMode == DigestSync {
+			set := e.newSyncDigestSet(t)
+			// self digest is in every page, so that the remote always tells whether it knows myself.
+			digestPages = set.pages(set.lookup(t.Names(e.cluster.Self())), limit)
+		} else {
+			snap, names := e.newSyncClusterSnapshotWithNames(t)
+			if limit > 0 {
+				sortSnapshotNodes(snap.Nodes, names)
+			}
+			pages = paginateNodes(snap.Nodes, limit, 0)
+		}
 
 		return false
 	}, sladder.MembershipModification())
@@ -100,19 +114,59 @@ func (e *EngineInstance) ClusterSync() {
 	for _, node := range nodes {
 		e.lock.Lock()
 		id := e._generateMessageID()
+		cursor := e.syncPageCursor // rotate pages.
+		e.syncPageCursor++
 		e.lock.Unlock()
 
-		e.sendProto(node, &pb.Sync{
-			Id:      id,
-			Cluster: snap,
-			Type:    pb.Sync_PushPull,
-		})
+		msg := &pb.Sync{Id: id}
+		numOfPages := len(pages)
+		if e.syncMode == DigestSync {
+			numOfPages = len(digestPages)
+		}
+		if numOfPages < 1 {
+			continue
+		}
+		page := cursor % uint32(numOfPages)
+		if numOfPages > 1 {
+			msg.Page, msg.Pages = page, uint32(numOfPages)
+		}
 
-		minc.PushPull++
+		if e.syncMode == DigestSync {
+			msg.Type, msg.Digests = pb.Sync_Digest, digestPages[page]
+			minc.Digest++
+		} else {
+			msg.Type, msg.Cluster = pb.Sync_PushPull, &proto.Cluster{Nodes: pages[page]}
+			minc.PushPull++
+		}
+		e.sendProto(node, msg)
 	}
 
 }
 
+// sendSyncPages sends nodes in pages, and returns number of pages sent.
+// The first page is sent with type ty and digests, and the others are sent with type restTy.
+func (e *EngineInstance) sendSyncPages(to []string, id uint64, ty, restTy pb.Sync_Type, nodes []*proto.Node, digests []*pb.NodeDigest) int {
+	pages := paginateNodes(nodes, e.maxSyncMessageSize, encodedSizeOfDigests(digests))
+	if len(pages) < 1 {
+		pages = [][]*proto.Node{nil}
+	}
+	for idx, page := range pages {
+		msg := &pb.Sync{
+			Id:      id,
+			Type:    restTy,
+			Cluster: &proto.Cluster{Nodes: page},
+		}
+		if idx == 0 {
+			msg.Type, msg.Digests = ty, digests
+		}
+		if len(pages) > 1 {
+			msg.Page, msg.Pages = uint32(idx), uint32(len(pages))
+		}
+		e.sendProto(to, msg)
+	}
+	return len(pages)
+}
+
 func (e *EngineInstance) processSyncGossipProto(from []string, msg *pb.GossipMessage) {
 	if msg == nil {
 		return
@@ -143,22 +197,50 @@ func (e *EngineInstance) processSyncGossipProto(from []string, msg *pb.GossipMes
 		needPush = true
 		minc.IncomingPushPull++
 
+	case pb.Sync_Digest:
+		minc.IncomingDigest++
+		e.processSyncDigest(from, &sync, minc)
+		return
+
+	case pb.Sync_DigestPushPull:
+		needPush = true
+		minc.IncomingPushPull++
+
+	case pb.Sync_DigestPush:
+		needPush = false
+		minc.IncomingPush++
+
+	case pb.Sync_Eager:
+		needPush = false
+		minc.IncomingPush++
+
 	default:
 		return
 	}
+	if sync.Cluster == nil {
+		sync.Cluster = &proto.Cluster{}
+	}
+
+	// message ID of DigestPush and Eager is generated by remote, so it cannot be used to trace self existence.
+	traceExistence := sync.Type != pb.Sync_DigestPush && sync.Type != pb.Sync_Eager &&
+		(!needPush || sync.Type == pb.Sync_DigestPushPull)
 
-	asyncSendPush := func(snap *proto.Cluster) {
+	pushType := pb.Sync_Push
+	if sync.Type == pb.Sync_DigestPushPull {
+		pushType = pb.Sync_DigestPush
+	}
+	asyncSendPush := func(nodes []*proto.Node) {
 		e.arbiter.Go(func() {
-			e.sendProto(from, &pb.Sync{
-				Id:      sync.Id,
-				Cluster: snap,
-				Type:    pb.Sync_Push,
-			})
-			minc.Push++
+			pages := e.sendSyncPages(from, sync.Id, pushType, pushType, nodes, nil)
+			e.Metrics.Sync.ApplyIncrement(&SyncMetricIncrement{Push: uint64(pages)})
 		})
 	}
 
-	var errs sladder.Errors
+	var (
+		errs      sladder.Errors
+		fromNode  *sladder.Node
+		suspected map[*sladder.Node]uint32 // suspected nodes reported by the remote, with tag versions.
+	)
 	if err := e.cluster.Txn(func(t *sladder.Transaction) bool {
 		type relatedInfo struct {
 			node      *sladder.Node
@@ -168,7 +250,8 @@ func (e *EngineInstance) processSyncGossipProto(from []string, msg *pb.GossipMes
 		}
 		var err error
 
-		fromNode, selfSeen := t.MostPossibleNode(from), false
+		fromNode, suspected = t.MostPossibleNode(from), nil
+		selfSeen, selfLatest := false, false // whether the remote knows myself, and whether it knows the latest of myself.
 
 		// mark txn internal.
 		e.innerTxnIDs.Store(t.ID(), struct{}{})
@@ -177,6 +260,18 @@ func (e *EngineInstance) processSyncGossipProto(from []string, msg *pb.GossipMes
 
 		fastPush := true
 
+		if sync.Type == pb.Sync_DigestPushPull {
+			// the remote knows the latest of myself unless it wants myself.
+			selfSeen = true
+			for _, digest := range sync.Digests {
+				if t.MostPossibleNode(digest.Names) == self {
+					selfSeen = false
+					break
+				}
+			}
+			selfLatest = selfSeen
+		}
+
 		infos := make([]relatedInfo, len(sync.Cluster.Nodes))
 		for idx, mnode := range sync.Cluster.Nodes { // build relation info.
 
@@ -216,11 +311,22 @@ func (e *EngineInstance) processSyncGossipProto(from []string, msg *pb.GossipMes
 
 		if needPush {
 			// send response.
-			snap := e.newSyncClusterSnapshot(t)
+			var nodes []*proto.Node
+			if sync.Type == pb.Sync_DigestPushPull {
+				nodes = e.newSyncDigestSet(t).selectNodes(sync.Digests)
+			} else {
+				snap, names := e.newSyncClusterSnapshotWithNames(t)
+				if e.maxSyncMessageSize > 0 {
+					// the first page begins with the remote node, so the remote can tell whether itself is known.
+					sortSnapshotNodes(snap.Nodes, names)
+					pinSnapshotNode(snap.Nodes, names, from)
+				}
+				nodes = snap.Nodes
+			}
 			if fastPush {
-				asyncSendPush(snap)
+				asyncSendPush(nodes)
 			} else {
-				t.DeferOnCommit(func() { asyncSendPush(snap) })
+				t.DeferOnCommit(func() { asyncSendPush(nodes) })
 			}
 		}
 
@@ -303,7 +409,7 @@ func (e *EngineInstance) processSyncGossipProto(from []string, msg *pb.GossipMes
 							e.log.Warnf("a leaving node is with invalid SWIM tag. (decode err = \"%v\").", err.Error())
 							e.untraceLeaveingNode(leaving)
 							node = nil
-						} else if tag.Version <= leavingTag.Version {
+						} else if !tag.Succeeds(leavingTag) {
 							// this is a lag message. won't be accepted.
 							continue
 						} else {
@@ -326,15 +432,26 @@ func (e *EngineInstance) processSyncGossipProto(from []string, msg *pb.GossipMes
 					continue
 				}
 
-				if !needPush && node == self {
-					selfSeen = true // the remote knows myself.
+				if node == self {
+					if sync.Type == pb.Sync_DigestPushPull {
+						// the remote has different view of myself, but it still knows myself.
+						selfSeen, selfLatest = true, false
+					} else if !needPush {
+						selfSeen, selfLatest = true, true // the remote knows myself.
+					}
+				} else if info.tagInMsg != nil && info.tagInMsg.State == SUSPECTED {
+					if suspected == nil {
+						suspected = make(map[*sladder.Node]uint32)
+					}
+					suspected[node] = info.tagInMsg.Version
 				}
 			}
 
 			useNewNode := false
 
 			// stage: may apply swim tag first. (if any)
-			if tagKeyIndex > 0 {
+			// the tag may be the first entry, so index 0 must be accepted too.
+			if tagKeyIndex >= 0 {
 				tempNode := proto.Node{
 					Kvs: []*proto.Node_KeyValue{mnode.Kvs[tagKeyIndex]},
 				}
@@ -394,7 +511,13 @@ func (e *EngineInstance) processSyncGossipProto(from []string, msg *pb.GossipMes
 			}
 		}
 
-		if !needPush {
+		if fromNode == nil {
+			// the remote may be introduced by this message. (e.g. joining)
+			fromNode = t.MostPossibleNode(from)
+		}
+
+		// for paginated message, self absence can only be told by the first page.
+		if traceExistence && (selfSeen || sync.Pages < 2 || sync.Page == 0) {
 			rawMessageID := sync.Id - e.counterSeed
 
 			// stage: trace self existence seen by others.
@@ -412,7 +535,7 @@ func (e *EngineInstance) processSyncGossipProto(from []string, msg *pb.GossipMes
 				e.lock.Unlock()
 			}
 
-			if e.quitAfter > 0 && selfSeen { // quiting. determine whether quit condition is reached.
+			if e.quitAfter > 0 && selfLatest { // quiting. determine whether quit condition is reached.
 				// ensure LEAVE state included in this synchronizaion.
 				if rawMessageID >= e.quitAfter {
 					// LEAVE state has spreaded.
@@ -432,6 +555,14 @@ func (e *EngineInstance) processSyncGossipProto(from []string, msg *pb.GossipMes
 		return true
 	}, sladder.MembershipModification()); err != nil { // in order to lock entire cluster, we are required to use MembershipModification().
 		errs = append(errs, err)
+	} else {
+		if sync.Type != pb.Sync_Eager { // eager push carries only changes of the remote.
+			e.cluster.Synced()
+		}
+		if fromNode != nil && len(suspected) > 0 {
+			// the remote confirms suspections.
+			e.confirmSuspections(fromNode, suspected)
+		}
 	}
 
 	if err := errs.AsError(); err != nil {
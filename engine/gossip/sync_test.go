@@ -11,6 +11,10 @@ import (
 	"time"
 
 	"github.com/crossmesh/sladder"
+	"github.com/crossmesh/sladder/engine/gossip/pb"
+	"github.com/crossmesh/sladder/engine/gossip/simnet"
+	"github.com/crossmesh/sladder/proto"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -237,7 +241,7 @@ func syncLoop(t *testing.T, vps []*testClusterViewPoint, round int,
 
 func newHealthyClusterGod(t *testing.T, namePrefix string, numOfName, numOfNode int,
 	engineOptions []sladder.EngineOption,
-	clusterOptions []sladder.ClusterOption) (god *testClusterGod, ctl *TestTransportControl, err error) {
+	clusterOptions []sladder.ClusterOption) (god *testClusterGod, ctl *simnet.Network, err error) {
 
 	god, ctl, err = newClusterGod("tst", numOfName, numOfNode, engineOptions, clusterOptions)
 
@@ -505,3 +509,37 @@ func TestGossipEngineSync(t *testing.T) {
 		})
 	})
 }
+
+func TestSyncTagAsFirstEntry(t *testing.T) {
+	god, ctl, err := newClusterGod("tst", 1, 1, nil, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer god.Detach(ctl)
+
+	vp := god.VPList()[0]
+	assert.NoError(t, vp.cv.RegisterKey("key1", sladder.StringValidator{}, true, 0))
+
+	// key1 is not in entry list of the tag, which comes first.
+	tag := &SWIMTag{Version: 1, State: ALIVE, EntryList: []string{"idkey"}}
+	body, err := ptypes.MarshalAny(&pb.Sync{Id: 1, Type: pb.Sync_Push, Cluster: &proto.Cluster{
+		Nodes: []*proto.Node{{Kvs: []*proto.Node_KeyValue{
+			{Key: vp.engine.swimTagKey, Value: tag.Encode()},
+			{Key: "idkey", Value: `{"ns":["remote"],"v":1}`},
+			{Key: "key1", Value: "v"},
+		}}},
+	}})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	vp.engine.processSyncGossipProto([]string{"remote"}, &pb.GossipMessage{Type: pb.GossipMessage_Sync, Body: body})
+
+	n := vp.cv.GetNode("remote")
+	if assert.NotNil(t, n) {
+		var keys []string
+		for _, kv := range n.KeyValueEntries(true) {
+			keys = append(keys, kv.Key)
+		}
+		assert.ElementsMatch(t, []string{vp.engine.swimTagKey, "idkey"}, keys)
+	}
+}
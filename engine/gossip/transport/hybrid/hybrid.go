@@ -0,0 +1,165 @@
+package hybrid
+
+import (
+	"context"
+
+	"github.com/crossmesh/sladder/engine/gossip"
+	"github.com/crossmesh/sladder/engine/gossip/pb"
+	arbit "github.com/sunmxt/arbiter"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// DefaultSizeThreshold is default payload size above which messages go through reliable transport.
+	// It keeps unreliable payloads small enough to avoid IP fragmentation on most networks.
+	DefaultSizeThreshold = 1400
+)
+
+// Option contains transport parameters.
+type Option interface{}
+
+type sizeThreshold int
+
+// WithSizeThreshold creates option of size threshold.
+// Payloads larger than threshold will be sent by reliable transport despite of message type.
+func WithSizeThreshold(n int) Option { return sizeThreshold(n) }
+
+type reliableTypes []pb.GossipMessage_Type
+
+// WithReliableTypes creates option of message types which should be sent by reliable transport.
+func WithReliableTypes(types ...pb.GossipMessage_Type) Option { return reliableTypes(types) }
+
+type inboundMessage struct {
+	from []string
+	buf  []byte
+}
+
+// Transport routes failure detection traffic to unreliable transport and state sync traffic to reliable one.
+// Incoming messages from both transports are merged.
+//
+// Payload is recognized as pb.GossipMessage to determine message type.
+// pb.Sealed carries message type in the same field, so sealed messages are routed in the same way.
+// Payloads which cannot be recognized are routed by size only.
+type Transport struct {
+	unreliable, reliable gossip.Transport
+
+	sizeThreshold int
+	reliableTypes map[pb.GossipMessage_Type]struct{}
+
+	incoming chan *inboundMessage
+	arbiter  *arbit.Arbiter
+}
+
+// New creates hybrid transport.
+// Underlying transports are owned by caller and will not be closed by hybrid transport.
+func New(unreliable, reliable gossip.Transport, options ...Option) *Transport {
+	if unreliable == nil || reliable == nil {
+		panic("transport is nil")
+	}
+
+	t := &Transport{
+		unreliable:    unreliable,
+		reliable:      reliable,
+		sizeThreshold: DefaultSizeThreshold,
+		reliableTypes: map[pb.GossipMessage_Type]struct{}{
+			pb.GossipMessage_Sync: {},
+		},
+		incoming: make(chan *inboundMessage),
+		arbiter:  arbit.New(),
+	}
+	for _, option := range options {
+		switch v := option.(type) {
+		case sizeThreshold:
+			t.sizeThreshold = int(v)
+		case reliableTypes:
+			t.reliableTypes = make(map[pb.GossipMessage_Type]struct{}, len(v))
+			for _, ty := range v {
+				t.reliableTypes[ty] = struct{}{}
+			}
+		}
+	}
+
+	t.arbiter.Go(func() { t.pump(t.unreliable) })
+	t.arbiter.Go(func() { t.pump(t.reliable) })
+
+	return t
+}
+
+// Close stops receiving from underlying transports.
+func (t *Transport) Close() error {
+	t.arbiter.Shutdown()
+	t.arbiter.Join()
+	return nil
+}
+
+func (t *Transport) pump(from gossip.Transport) {
+	ctx := t.arbiter.Context()
+	for t.arbiter.ShouldRun() {
+		names, buf := from.Receive(ctx)
+		if len(names) < 1 || buf == nil {
+			continue
+		}
+		select {
+		case t.incoming <- &inboundMessage{from: names, buf: buf}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// messageType peeks type of raw pb.GossipMessage.
+func messageType(raw []byte) (ty pb.GossipMessage_Type, ok bool) {
+	ty = pb.GossipMessage_Ping // zero value is omitted in encoding.
+	for len(raw) > 0 {
+		num, wtyp, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return ty, false
+		}
+		raw = raw[n:]
+		if num == 1 && wtyp == protowire.VarintType {
+			v, n := protowire.ConsumeVarint(raw)
+			if n < 0 {
+				return ty, false
+			}
+			ty, raw = pb.GossipMessage_Type(v), raw[n:]
+			continue
+		}
+		if n = protowire.ConsumeFieldValue(num, wtyp, raw); n < 0 {
+			return ty, false
+		}
+		raw = raw[n:]
+	}
+	return ty, true
+}
+
+func (t *Transport) shouldBeReliable(buf []byte) bool {
+	if t.sizeThreshold > 0 && len(buf) > t.sizeThreshold {
+		return true
+	}
+	ty, ok := messageType(buf)
+	if !ok {
+		return false
+	}
+	_, reliable := t.reliableTypes[ty]
+	return reliable
+}
+
+// Send sends message through reliable or unreliable transport.
+func (t *Transport) Send(names []string, buf []byte) {
+	if t.shouldBeReliable(buf) {
+		t.reliable.Send(names, buf)
+	} else {
+		t.unreliable.Send(names, buf)
+	}
+}
+
+// Receive receives message from any of underlying transports. It returns nil when the context is done.
+// After Close, no message arrives, so it blocks until the context is done as other transports do.
+func (t *Transport) Receive(ctx context.Context) ([]string, []byte) {
+	select {
+	case msg := <-t.incoming:
+		return msg.from, msg.buf
+	case <-ctx.Done():
+	}
+	return nil, nil
+}
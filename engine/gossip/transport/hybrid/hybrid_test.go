@@ -0,0 +1,153 @@
+package hybrid
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder/engine/gossip"
+	"github.com/crossmesh/sladder/engine/gossip/pb"
+	spb "github.com/crossmesh/sladder/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+var _ gossip.Transport = (*Transport)(nil)
+
+type recordTransport struct {
+	lock     sync.Mutex
+	sent     [][]byte
+	incoming chan []byte
+}
+
+func newRecordTransport() *recordTransport {
+	return &recordTransport{incoming: make(chan []byte, 1)}
+}
+
+func (t *recordTransport) Send(names []string, buf []byte) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.sent = append(t.sent, buf)
+}
+
+func (t *recordTransport) Receive(ctx context.Context) ([]string, []byte) {
+	select {
+	case buf := <-t.incoming:
+		return []string{"peer"}, buf
+	case <-ctx.Done():
+	}
+	return nil, nil
+}
+
+func (t *recordTransport) numOfSent() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return len(t.sent)
+}
+
+func marshalGossipMessage(t *testing.T, body protoiface.MessageV1) []byte {
+	var err error
+	msg := &pb.GossipMessage{}
+	if msg.Body, err = ptypes.MarshalAny(body); !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	msg.Type = pb.GossipMessageTypeID[reflect.TypeOf(body)]
+	raw, err := proto.Marshal(msg)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return raw
+}
+
+func TestHybridTransport(t *testing.T) {
+	t.Run("message_type", func(t *testing.T) {
+		for body, expected := range map[protoiface.MessageV1]pb.GossipMessage_Type{
+			&pb.Ping{Id: 1}:                          pb.GossipMessage_Ping,
+			&pb.Ack{Id: 1}:                           pb.GossipMessage_Ack,
+			&pb.PingReq{Id: 1, Name: []string{"a"}}:  pb.GossipMessage_PingReq,
+			&pb.Sync{Id: 1, Cluster: &spb.Cluster{}}: pb.GossipMessage_Sync,
+		} {
+			ty, ok := messageType(marshalGossipMessage(t, body))
+			assert.True(t, ok)
+			assert.Equal(t, expected, ty)
+		}
+		_, ok := messageType([]byte{0x00, 0x01, 0x02})
+		assert.False(t, ok)
+	})
+
+	t.Run("route", func(t *testing.T) {
+		unreliable, reliable := newRecordTransport(), newRecordTransport()
+		tp := New(unreliable, reliable, WithSizeThreshold(64))
+		defer tp.Close()
+
+		tp.Send([]string{"peer"}, marshalGossipMessage(t, &pb.Ping{Id: 1}))
+		tp.Send([]string{"peer"}, marshalGossipMessage(t, &pb.Ack{Id: 1}))
+		assert.Equal(t, 2, unreliable.numOfSent())
+		assert.Equal(t, 0, reliable.numOfSent())
+
+		tp.Send([]string{"peer"}, marshalGossipMessage(t, &pb.Sync{Id: 1}))
+		assert.Equal(t, 1, reliable.numOfSent())
+
+		// large ping-req.
+		names := make([]string, 0, 16)
+		for i := 0; i < 16; i++ {
+			names = append(names, "long-long-node-name")
+		}
+		tp.Send([]string{"peer"}, marshalGossipMessage(t, &pb.PingReq{Id: 1, Name: names}))
+		assert.Equal(t, 2, reliable.numOfSent())
+
+		// unrecognized payload.
+		tp.Send([]string{"peer"}, []byte{0x00, 0x01})
+		assert.Equal(t, 3, unreliable.numOfSent())
+	})
+
+	t.Run("reliable_types", func(t *testing.T) {
+		unreliable, reliable := newRecordTransport(), newRecordTransport()
+		tp := New(unreliable, reliable, WithSizeThreshold(0), WithReliableTypes(pb.GossipMessage_Sync, pb.GossipMessage_PingReq))
+		defer tp.Close()
+
+		tp.Send([]string{"peer"}, marshalGossipMessage(t, &pb.PingReq{Id: 1}))
+		tp.Send([]string{"peer"}, marshalGossipMessage(t, &pb.Ping{Id: 1}))
+		assert.Equal(t, 1, reliable.numOfSent())
+		assert.Equal(t, 1, unreliable.numOfSent())
+	})
+
+	t.Run("receive", func(t *testing.T) {
+		assert.Panics(t, func() { New(nil, newRecordTransport()) })
+
+		unreliable, reliable := newRecordTransport(), newRecordTransport()
+		tp := New(unreliable, reliable)
+
+		unreliable.incoming <- []byte("u")
+		reliable.incoming <- []byte("r")
+		received := map[string]struct{}{}
+		for i := 0; i < 2; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			from, buf := tp.Receive(ctx)
+			cancel()
+			assert.Equal(t, []string{"peer"}, from)
+			received[string(buf)] = struct{}{}
+		}
+		assert.Equal(t, map[string]struct{}{"u": {}, "r": {}}, received)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		from, buf := tp.Receive(ctx)
+		cancel()
+		assert.Nil(t, from)
+		assert.Nil(t, buf)
+
+		// blocks until context is done after closed.
+		assert.NoError(t, tp.Close())
+		ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*50)
+		start := time.Now()
+		from, buf = tp.Receive(ctx)
+		cancel()
+		assert.True(t, time.Since(start) >= time.Millisecond*50)
+		assert.Nil(t, from)
+		assert.Nil(t, buf)
+	})
+}
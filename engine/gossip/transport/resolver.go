@@ -0,0 +1,116 @@
+package transport
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrEndpointNotFound = errors.New("no endpoint found for node")
+)
+
+// Resolver maps node names to network endpoints.
+type Resolver interface {
+	// Endpoint returns "host:port" endpoint of the node with given names.
+	Endpoint(names []string) (string, error)
+
+	// Names returns names bound to given endpoint.
+	Names(endpoint string) []string
+}
+
+// AddressResolver treats node names as "host:port" endpoints.
+type AddressResolver struct{}
+
+// Endpoint returns the first name in form of "host:port".
+func (r AddressResolver) Endpoint(names []string) (string, error) {
+	for _, name := range names {
+		if _, _, err := net.SplitHostPort(name); err == nil {
+			return name, nil
+		}
+	}
+	return "", ErrEndpointNotFound
+}
+
+// Names returns the endpoint itself as node name.
+func (r AddressResolver) Names(endpoint string) []string { return []string{endpoint} }
+
+// StaticResolver maps node names to endpoints by static bindings.
+type StaticResolver struct {
+	lock sync.RWMutex
+
+	byName     map[string]string
+	byEndpoint map[string][]string // (sorted)
+}
+
+// NewStaticResolver creates new static resolver.
+func NewStaticResolver() *StaticResolver {
+	return &StaticResolver{
+		byName:     make(map[string]string),
+		byEndpoint: make(map[string][]string),
+	}
+}
+
+func (r *StaticResolver) unbind(name string) {
+	endpoint, exists := r.byName[name]
+	if !exists {
+		return
+	}
+	delete(r.byName, name)
+
+	names := r.byEndpoint[endpoint]
+	if idx := sort.SearchStrings(names, name); idx < len(names) && names[idx] == name {
+		names = append(names[:idx], names[idx+1:]...)
+	}
+	if len(names) < 1 {
+		delete(r.byEndpoint, endpoint)
+	} else {
+		r.byEndpoint[endpoint] = names
+	}
+}
+
+// Bind binds names to endpoint.
+func (r *StaticResolver) Bind(endpoint string, names ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, name := range names {
+		r.unbind(name)
+		r.byName[name] = endpoint
+		bound := append(r.byEndpoint[endpoint], name)
+		sort.Strings(bound)
+		r.byEndpoint[endpoint] = bound
+	}
+}
+
+// Unbind removes bindings of names.
+func (r *StaticResolver) Unbind(names ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, name := range names {
+		r.unbind(name)
+	}
+}
+
+// Endpoint returns endpoint bound to any of names.
+func (r *StaticResolver) Endpoint(names []string) (string, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for _, name := range names {
+		if endpoint, exists := r.byName[name]; exists {
+			return endpoint, nil
+		}
+	}
+	return "", ErrEndpointNotFound
+}
+
+// Names returns names bound to endpoint.
+func (r *StaticResolver) Names(endpoint string) (names []string) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return append(names, r.byEndpoint[endpoint]...)
+}
@@ -0,0 +1,42 @@
+package transport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolver(t *testing.T) {
+	t.Run("address", func(t *testing.T) {
+		r := AddressResolver{}
+		endpoint, err := r.Endpoint([]string{"node-1", "127.0.0.1:7000"})
+		assert.NoError(t, err)
+		assert.Equal(t, "127.0.0.1:7000", endpoint)
+
+		_, err = r.Endpoint([]string{"node-1"})
+		assert.Equal(t, ErrEndpointNotFound, err)
+
+		assert.Equal(t, []string{"127.0.0.1:7000"}, r.Names("127.0.0.1:7000"))
+	})
+
+	t.Run("static", func(t *testing.T) {
+		r := NewStaticResolver()
+		r.Bind("127.0.0.1:7000", "b", "a")
+		r.Bind("127.0.0.1:7001", "c")
+
+		endpoint, err := r.Endpoint([]string{"x", "b"})
+		assert.NoError(t, err)
+		assert.Equal(t, "127.0.0.1:7000", endpoint)
+		assert.Equal(t, []string{"a", "b"}, r.Names("127.0.0.1:7000"))
+
+		// rebind.
+		r.Bind("127.0.0.1:7001", "a")
+		assert.Equal(t, []string{"b"}, r.Names("127.0.0.1:7000"))
+		assert.Equal(t, []string{"a", "c"}, r.Names("127.0.0.1:7001"))
+
+		r.Unbind("b")
+		assert.Empty(t, r.Names("127.0.0.1:7000"))
+		_, err = r.Endpoint([]string{"b"})
+		assert.Equal(t, ErrEndpointNotFound, err)
+	})
+}
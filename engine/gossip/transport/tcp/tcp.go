@@ -0,0 +1,483 @@
+package tcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	"github.com/crossmesh/sladder/engine/gossip/transport"
+	arbit "github.com/sunmxt/arbiter"
+)
+
+const (
+	// DefaultMaxFrameSize is default limitation of frame size.
+	DefaultMaxFrameSize = 32 * 1024 * 1024
+	// DefaultIdleTimeout is default timeout after which an idle connection is reaped.
+	DefaultIdleTimeout = time.Minute
+	// DefaultDialTimeout is default timeout of connecting to peer.
+	DefaultDialTimeout = time.Second * 5
+	// DefaultWriteTimeout is default timeout of writing one frame.
+	DefaultWriteTimeout = time.Second * 10
+	// DefaultSendQueueSize is default number of frames could be queued for one peer.
+	DefaultSendQueueSize = 64
+
+	handshakeVersion = uint8(1)
+	// maxHandshakeSize limits handshake frame from unauthenticated peer.
+	maxHandshakeSize = 4096
+)
+
+var (
+	ErrFrameTooLarge     = errors.New("frame too large")
+	ErrInvalidHandshake  = errors.New("invalid handshake")
+	ErrSendQueueOverflow = errors.New("send queue overflow")
+	ErrTransportClosed   = errors.New("transport closed")
+)
+
+// Option contains transport parameters.
+type Option interface{}
+
+type resolver struct{ transport.Resolver }
+
+// WithResolver creates option of endpoint resolver.
+func WithResolver(r transport.Resolver) Option { return resolver{r} }
+
+type logger struct{ sladder.Logger }
+
+// WithLogger creates option of transport logger.
+// Failures of accepting connections, handshakes, and frames dropped or failed to be sent are reported to it.
+func WithLogger(log sladder.Logger) Option { return logger{log} }
+
+type advertiseNames []string
+
+// WithAdvertiseNames creates option of names with which peers identify the local node.
+// If not given, address of listener will be advertised.
+func WithAdvertiseNames(names ...string) Option { return advertiseNames(names) }
+
+type maxFrameSize int
+
+// WithMaxFrameSize creates option to limit frame size.
+func WithMaxFrameSize(n int) Option { return maxFrameSize(n) }
+
+type idleTimeout time.Duration
+
+// WithIdleTimeout creates option of idle timeout, after which an unused connection will be closed.
+func WithIdleTimeout(d time.Duration) Option { return idleTimeout(d) }
+
+type dialTimeout time.Duration
+
+// WithDialTimeout creates option of connecting timeout.
+func WithDialTimeout(d time.Duration) Option { return dialTimeout(d) }
+
+type writeTimeout time.Duration
+
+// WithWriteTimeout creates option of timeout of writing one frame.
+func WithWriteTimeout(d time.Duration) Option { return writeTimeout(d) }
+
+type sendQueueSize int
+
+// WithSendQueueSize creates option of per-peer send queue size.
+// Frames will be dropped when queue is full.
+func WithSendQueueSize(n int) Option { return sendQueueSize(n) }
+
+type inboundFrame struct {
+	from []string
+	buf  []byte
+}
+
+type peer struct {
+	names []string
+	queue chan []byte
+}
+
+// Transport implements gossip transport over TCP streams.
+// Frames are length-prefixed. Outbound connections are pooled by node name and reaped after idle timeout.
+type Transport struct {
+	listener net.Listener
+	resolver transport.Resolver
+	log      sladder.Logger
+	names    []string
+
+	maxFrameSize  int
+	idleTimeout   time.Duration
+	dialTimeout   time.Duration
+	writeTimeout  time.Duration
+	sendQueueSize int
+
+	lock  sync.Mutex
+	pool  map[string]*peer
+	conns map[net.Conn]struct{}
+
+	incoming chan *inboundFrame
+	arbiter  *arbit.Arbiter
+}
+
+// New listens on bind address and creates transport.
+func New(bind string, options ...Option) (*Transport, error) {
+	t := &Transport{
+		resolver:      transport.AddressResolver{},
+		log:           sladder.DefaultLogger,
+		maxFrameSize:  DefaultMaxFrameSize,
+		idleTimeout:   DefaultIdleTimeout,
+		dialTimeout:   DefaultDialTimeout,
+		writeTimeout:  DefaultWriteTimeout,
+		sendQueueSize: DefaultSendQueueSize,
+
+		pool:     make(map[string]*peer),
+		conns:    make(map[net.Conn]struct{}),
+		incoming: make(chan *inboundFrame, DefaultSendQueueSize),
+	}
+	for _, option := range options {
+		switch v := option.(type) {
+		case resolver:
+			if v.Resolver != nil {
+				t.resolver = v.Resolver
+			}
+		case logger:
+			if v.Logger != nil {
+				t.log = v.Logger
+			}
+		case advertiseNames:
+			t.names = append(t.names[:0], v...)
+		case maxFrameSize:
+			if v > 0 {
+				t.maxFrameSize = int(v)
+			}
+		case idleTimeout:
+			if v > 0 {
+				t.idleTimeout = time.Duration(v)
+			}
+		case dialTimeout:
+			if v > 0 {
+				t.dialTimeout = time.Duration(v)
+			}
+		case writeTimeout:
+			if v > 0 {
+				t.writeTimeout = time.Duration(v)
+			}
+		case sendQueueSize:
+			if v > 0 {
+				t.sendQueueSize = int(v)
+			}
+		}
+	}
+
+	var err error
+	if t.listener, err = net.Listen("tcp", bind); err != nil {
+		return nil, err
+	}
+	if len(t.names) < 1 {
+		t.names = []string{t.listener.Addr().String()}
+	}
+
+	t.arbiter = arbit.New()
+	t.arbiter.Go(t.acceptConnections)
+	t.arbiter.Go(func() {
+		<-t.arbiter.Exit()
+
+		t.listener.Close()
+
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		for conn := range t.conns {
+			conn.Close()
+		}
+	})
+
+	return t, nil
+}
+
+// LocalAddr returns listening address.
+func (t *Transport) LocalAddr() net.Addr { return t.listener.Addr() }
+
+// Close shutdowns transport and closes all connections.
+func (t *Transport) Close() error {
+	t.arbiter.Shutdown()
+	t.arbiter.Join()
+	return nil
+}
+
+func (t *Transport) trackConn(conn net.Conn) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !t.arbiter.ShouldRun() {
+		return false
+	}
+	t.conns[conn] = struct{}{}
+	return true
+}
+
+func (t *Transport) untrackConn(conn net.Conn) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.conns, conn)
+	conn.Close()
+}
+
+func writeFrame(conn net.Conn, buf []byte) (err error) {
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(buf)))
+	bufs := net.Buffers{header[:], buf}
+	_, err = bufs.WriteTo(conn)
+	return err
+}
+
+func readFrame(r io.Reader, limit int) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if uint64(size) > uint64(limit) {
+		return nil, ErrFrameTooLarge
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func encodeHandshake(names []string) []byte {
+	buf := make([]byte, 1, 1+binary.MaxVarintLen64)
+	buf[0] = handshakeVersion
+	buf = appendUvarint(buf, uint64(len(names)))
+	for _, name := range names {
+		buf = appendUvarint(buf, uint64(len(name)))
+		buf = append(buf, name...)
+	}
+	return buf
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var raw [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(raw[:], x)
+	return append(buf, raw[:n]...)
+}
+
+func decodeHandshake(buf []byte) (names []string, err error) {
+	if len(buf) < 1 || buf[0] != handshakeVersion {
+		return nil, ErrInvalidHandshake
+	}
+	buf = buf[1:]
+	count, n := binary.Uvarint(buf)
+	if n <= 0 || count > uint64(len(buf)) {
+		return nil, ErrInvalidHandshake
+	}
+	buf = buf[n:]
+	for ; count > 0; count-- {
+		size, n := binary.Uvarint(buf)
+		if n <= 0 || size > uint64(len(buf)-n) {
+			return nil, ErrInvalidHandshake
+		}
+		names = append(names, string(buf[n:n+int(size)]))
+		buf = buf[n+int(size):]
+	}
+	if len(names) < 1 {
+		return nil, ErrInvalidHandshake
+	}
+	return names, nil
+}
+
+func (t *Transport) acceptConnections() {
+	for t.arbiter.ShouldRun() {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			if t.arbiter.ShouldRun() {
+				t.log.Warnf("tcp transport failed to accept connection. (err = \"%v\")", err)
+				time.Sleep(time.Millisecond * 100)
+			}
+			continue
+		}
+		if !t.trackConn(conn) {
+			conn.Close()
+			break
+		}
+		t.arbiter.Go(func() { t.serveInbound(conn) })
+	}
+}
+
+func (t *Transport) serveInbound(conn net.Conn) {
+	defer t.untrackConn(conn)
+
+	r := bufio.NewReader(conn)
+
+	conn.SetReadDeadline(time.Now().Add(t.idleTimeout))
+	raw, err := readFrame(r, maxHandshakeSize)
+	if err != nil {
+		t.log.Warnf("tcp transport failed to read handshake. (err = \"%v\") {remote = %v}", err, conn.RemoteAddr())
+		return
+	}
+	from, err := decodeHandshake(raw)
+	if err != nil {
+		t.log.Warnf("tcp transport got invalid handshake. (err = \"%v\") {remote = %v}", err, conn.RemoteAddr())
+		return
+	}
+
+	for t.arbiter.ShouldRun() {
+		conn.SetReadDeadline(time.Now().Add(t.idleTimeout))
+		buf, err := readFrame(r, t.maxFrameSize)
+		if err != nil {
+			if nerr, ok := err.(net.Error); ok && nerr.Timeout() { // idle.
+				return
+			}
+			if err != io.EOF && t.arbiter.ShouldRun() {
+				t.log.Warnf("tcp transport failed to read frame. (err = \"%v\") {node = %v, remote = %v}", err, from, conn.RemoteAddr())
+			}
+			return
+		}
+
+		select {
+		case t.incoming <- &inboundFrame{from: from, buf: buf}:
+		case <-t.arbiter.Exit():
+			return
+		}
+	}
+}
+
+// Receive receives frame. It returns nil when the context is done.
+// After Close, it blocks until the context is done, so that callers looping on it never spin.
+func (t *Transport) Receive(ctx context.Context) (from []string, buf []byte) {
+	select {
+	case frame := <-t.incoming:
+		return frame.from, frame.buf
+	case <-ctx.Done():
+	}
+	return nil, nil
+}
+
+// Send queues frame to be sent to node.
+func (t *Transport) Send(names []string, buf []byte) {
+	if len(names) < 1 {
+		return
+	}
+	if len(buf) > t.maxFrameSize {
+		t.log.Warnf("tcp transport drops an outgoing frame. (err = \"%v\") {size = %v, limit = %v, node = %v}", ErrFrameTooLarge, len(buf), t.maxFrameSize, names)
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !t.arbiter.ShouldRun() {
+		return
+	}
+
+	var p *peer
+	for _, name := range names {
+		if p = t.pool[name]; p != nil {
+			break
+		}
+	}
+	if p == nil {
+		p = &peer{
+			names: append([]string(nil), names...),
+			queue: make(chan []byte, t.sendQueueSize),
+		}
+		t.arbiter.Go(func() { t.serveOutbound(p) })
+	}
+	for _, name := range names {
+		t.pool[name] = p
+	}
+
+	select {
+	case p.queue <- buf:
+	default:
+		t.log.Warnf("tcp transport drops an outgoing frame. (err = \"%v\") {node = %v}", ErrSendQueueOverflow, names)
+	}
+}
+
+// tryRemovePeer removes peer from pool if no more frame is queued.
+func (t *Transport) tryRemovePeer(p *peer, force bool) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !force && len(p.queue) > 0 && t.arbiter.ShouldRun() {
+		return false
+	}
+	for name, pooled := range t.pool {
+		if pooled == p {
+			delete(t.pool, name)
+		}
+	}
+	return true
+}
+
+func (t *Transport) dial(p *peer) (net.Conn, error) {
+	endpoint, err := t.resolver.Endpoint(p.names)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("tcp", endpoint, t.dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if !t.trackConn(conn) {
+		conn.Close()
+		return nil, ErrTransportClosed
+	}
+	conn.SetWriteDeadline(time.Now().Add(t.writeTimeout))
+	if err = writeFrame(conn, encodeHandshake(t.names)); err != nil {
+		t.untrackConn(conn)
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (t *Transport) serveOutbound(p *peer) {
+	var conn net.Conn
+
+	defer func() {
+		if conn != nil {
+			t.untrackConn(conn)
+		}
+	}()
+
+	idle := time.NewTimer(t.idleTimeout)
+	defer idle.Stop()
+
+	for {
+		select {
+		case <-t.arbiter.Exit():
+			t.tryRemovePeer(p, true)
+			return
+
+		case <-idle.C:
+			if t.tryRemovePeer(p, false) {
+				return
+			}
+			idle.Reset(t.idleTimeout)
+
+		case buf := <-p.queue:
+			var err error
+			if conn == nil {
+				conn, err = t.dial(p)
+			}
+			if err == nil {
+				conn.SetWriteDeadline(time.Now().Add(t.writeTimeout))
+				err = writeFrame(conn, buf)
+			}
+			if err != nil {
+				t.tryRemovePeer(p, true)
+				if dropped := len(p.queue); dropped > 0 {
+					t.log.Warnf("tcp transport failed to send frame. (err = \"%v\") {node = %v, dropped = %v}", err, p.names, dropped+1)
+				} else {
+					t.log.Warnf("tcp transport failed to send frame. (err = \"%v\") {node = %v}", err, p.names)
+				}
+				return
+			}
+
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(t.idleTimeout)
+		}
+	}
+}
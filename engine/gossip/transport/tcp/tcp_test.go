@@ -0,0 +1,252 @@
+package tcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	"github.com/crossmesh/sladder/engine/gossip"
+	"github.com/crossmesh/sladder/engine/gossip/transport/udp"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ gossip.Transport = (*Transport)(nil)
+
+func newTestTransport(t *testing.T, options ...Option) *Transport {
+	tp, err := New("127.0.0.1:0", options...)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return tp
+}
+
+func receiveWithTimeout(tp *Transport, d time.Duration) ([]string, []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return tp.Receive(ctx)
+}
+
+func TestTCPTransport(t *testing.T) {
+	t.Run("handshake", func(t *testing.T) {
+		names, err := decodeHandshake(encodeHandshake([]string{"a", "bb", ""}))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "bb", ""}, names)
+
+		for _, raw := range [][]byte{
+			nil, {0}, {handshakeVersion}, {handshakeVersion, 0},
+			{handshakeVersion, 1, 5, 'a'}, {handshakeVersion, 200},
+		} {
+			_, err = decodeHandshake(raw)
+			assert.Equal(t, ErrInvalidHandshake, err)
+		}
+	})
+
+	t.Run("oversized_handshake", func(t *testing.T) {
+		tp := newTestTransport(t, WithWriteTimeout(time.Second))
+		defer tp.Close()
+		assert.Equal(t, time.Second, tp.writeTimeout)
+
+		conn, err := net.Dial("tcp", tp.LocalAddr().String())
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		defer conn.Close()
+
+		// peer claiming a large handshake is rejected without reading it.
+		var header [4]byte
+		binary.BigEndian.PutUint32(header[:], maxHandshakeSize+1)
+		_, err = conn.Write(header[:])
+		assert.NoError(t, err)
+		conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+		_, err = conn.Read(make([]byte, 1))
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("frame", func(t *testing.T) {
+		server, client := net.Pipe()
+		defer server.Close()
+		defer client.Close()
+
+		payload := make([]byte, 4096)
+		rand.Read(payload)
+		go writeFrame(client, payload)
+		buf, err := readFrame(server, len(payload))
+		assert.NoError(t, err)
+		assert.Equal(t, payload, buf)
+
+		go writeFrame(client, payload)
+		_, err = readFrame(server, len(payload)-1)
+		assert.Equal(t, ErrFrameTooLarge, err)
+	})
+
+	t.Run("send_receive", func(t *testing.T) {
+		t1, t2 := newTestTransport(t), newTestTransport(t, WithAdvertiseNames("n2"))
+		defer t1.Close()
+		defer t2.Close()
+
+		large := make([]byte, 4*1024*1024)
+		rand.Read(large)
+
+		t1.Send([]string{t2.LocalAddr().String()}, []byte("hello"))
+		t1.Send([]string{t2.LocalAddr().String()}, large)
+
+		from, buf := receiveWithTimeout(t2, time.Second*5)
+		assert.Equal(t, []string{t1.LocalAddr().String()}, from)
+		assert.Equal(t, []byte("hello"), buf)
+		_, buf = receiveWithTimeout(t2, time.Second*5)
+		assert.True(t, bytes.Equal(large, buf))
+
+		// reply.
+		t2.Send(from, []byte("world"))
+		from, buf = receiveWithTimeout(t1, time.Second*5)
+		assert.Equal(t, []string{"n2"}, from)
+		assert.Equal(t, []byte("world"), buf)
+
+		t1.lock.Lock()
+		assert.Equal(t, 1, len(t1.pool))
+		t1.lock.Unlock()
+	})
+
+	t.Run("frame_limit", func(t *testing.T) {
+		t1, t2 := newTestTransport(t), newTestTransport(t, WithMaxFrameSize(4))
+		defer t1.Close()
+		defer t2.Close()
+
+		t1.Send([]string{t2.LocalAddr().String()}, []byte("hello"))
+		from, buf := receiveWithTimeout(t2, time.Millisecond*200)
+		assert.Nil(t, from)
+		assert.Nil(t, buf)
+	})
+
+	t.Run("idle_reaping", func(t *testing.T) {
+		t1, t2 := newTestTransport(t, WithIdleTimeout(time.Millisecond*100)), newTestTransport(t)
+		defer t1.Close()
+		defer t2.Close()
+
+		t1.Send([]string{t2.LocalAddr().String()}, []byte("hello"))
+		_, buf := receiveWithTimeout(t2, time.Second*5)
+		assert.Equal(t, []byte("hello"), buf)
+
+		time.Sleep(time.Millisecond * 400)
+		t1.lock.Lock()
+		assert.Equal(t, 0, len(t1.pool))
+		assert.Equal(t, 0, len(t1.conns))
+		t1.lock.Unlock()
+
+		// reconnect.
+		t1.Send([]string{t2.LocalAddr().String()}, []byte("again"))
+		_, buf = receiveWithTimeout(t2, time.Second*5)
+		assert.Equal(t, []byte("again"), buf)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		tp := newTestTransport(t, WithDialTimeout(time.Millisecond*200))
+		defer tp.Close()
+
+		tp.Send([]string{"unresolvable"}, []byte("hello"))
+		time.Sleep(time.Millisecond * 100)
+		tp.lock.Lock()
+		assert.Equal(t, 0, len(tp.pool))
+		tp.lock.Unlock()
+	})
+
+	t.Run("receive_context", func(t *testing.T) {
+		tp := newTestTransport(t)
+		from, buf := receiveWithTimeout(tp, time.Millisecond*100)
+		assert.Nil(t, from)
+		assert.Nil(t, buf)
+
+		// blocks until context is done after closed.
+		assert.NoError(t, tp.Close())
+		start := time.Now()
+		from, buf = receiveWithTimeout(tp, time.Millisecond*100)
+		assert.True(t, time.Since(start) >= time.Millisecond*100)
+		assert.Nil(t, from)
+		assert.Nil(t, buf)
+	})
+
+	t.Run("large_cluster_sync", func(t *testing.T) {
+		numOfNodes := 300
+
+		newCluster := func(e sladder.EngineInstance, name string) *sladder.Cluster {
+			c, _, err := sladder.NewClusterWithNameResolver(e, &sladder.TestNamesInKeyNameResolver{Key: "id"})
+			assert.NoError(t, err)
+			assert.NoError(t, c.RegisterKey("id", &sladder.TestNamesInKeyIDValidator{}, false, 0))
+			assert.NoError(t, c.RegisterKey("meta", sladder.StringValidator{}, false, 0))
+			assert.NoError(t, c.Txn(func(tx *sladder.Transaction) bool {
+				rtx, err := tx.KV(c.Self(), "id")
+				if !assert.NoError(t, err) {
+					return false
+				}
+				rtx.(*sladder.TestNamesInKeyTxn).AddName(name)
+				return true
+			}))
+			return c
+		}
+		options := []sladder.EngineOption{gossip.ManualSync(), gossip.ManualFailureDetect(), gossip.ManualClearSuspections()}
+
+		t1, t2 := newTestTransport(t), newTestTransport(t)
+		defer t1.Close()
+		defer t2.Close()
+		e1, e2 := gossip.New(t1, options...), gossip.New(t2, options...)
+		c1 := newCluster(e1, t1.LocalAddr().String())
+		c2 := newCluster(e2, t2.LocalAddr().String())
+
+		meta := string(bytes.Repeat([]byte("m"), 512))
+		newNode := func(tx *sladder.Transaction, name string, entries map[string]string) bool {
+			n, err := tx.NewNode()
+			if !assert.NoError(t, err) {
+				return false
+			}
+			rtx, err := tx.KV(n, "id")
+			if !assert.NoError(t, err) {
+				return false
+			}
+			rtx.(*sladder.TestNamesInKeyTxn).AddName(name)
+			if rtx, err = tx.KV(n, e1.(*gossip.EngineInstance).SWIMTagKey()); !assert.NoError(t, err) {
+				return false
+			}
+			tag := rtx.(*gossip.SWIMTagTxn)
+			tag.AddToEntryList("id")
+			for key, value := range entries {
+				if rtx, err = tx.KV(n, key); !assert.NoError(t, err) {
+					return false
+				}
+				rtx.(*sladder.StringTxn).Set(value)
+				tag.AddToEntryList(key)
+			}
+			return true
+		}
+		assert.NoError(t, c1.Txn(func(tx *sladder.Transaction) bool {
+			for i := 0; i < numOfNodes; i++ {
+				if !newNode(tx, fmt.Sprintf("node-%v", i), map[string]string{"meta": meta}) {
+					return false
+				}
+			}
+			return true
+		}, sladder.MembershipModification()))
+		assert.NoError(t, c2.Txn(func(tx *sladder.Transaction) bool {
+			return newNode(tx, t1.LocalAddr().String(), nil)
+		}, sladder.MembershipModification()))
+
+		// the snapshot cannot be carried by a datagram.
+		assert.Greater(t, numOfNodes*len(meta), udp.MaxPacketSize)
+
+		e2.(*gossip.EngineInstance).ClusterSync() // pull.
+		count := func() (n int) {
+			c2.RangeNodes(func(*sladder.Node) bool { n++; return true }, false, false)
+			return
+		}
+		for i := 0; i < 100 && count() < numOfNodes+2; i++ {
+			time.Sleep(time.Millisecond * 100)
+		}
+		assert.Equal(t, numOfNodes+2, count())
+	})
+}
@@ -0,0 +1,201 @@
+package udp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	"github.com/crossmesh/sladder/engine/gossip/transport"
+)
+
+const (
+	// DefaultMaxPacketSize is a payload size safe from IP fragmentation on most networks.
+	DefaultMaxPacketSize = 1400
+	// MaxPacketSize is the largest payload an UDP datagram can carry.
+	MaxPacketSize = 65507
+)
+
+var (
+	ErrPacketTooLarge = errors.New("packet too large")
+)
+
+// Option contains transport parameters.
+type Option interface{}
+
+type resolver struct{ transport.Resolver }
+
+// WithResolver creates option of endpoint resolver.
+func WithResolver(r transport.Resolver) Option { return resolver{r} }
+
+type logger struct{ sladder.Logger }
+
+// WithLogger creates option of transport logger.
+// Engine logger is recommended so that send errors could be found along with engine logs.
+func WithLogger(log sladder.Logger) Option { return logger{log} }
+
+type maxPacketSize int
+
+// WithMaxPacketSize creates option to limit packet size.
+// Packets larger then limitation will be dropped.
+func WithMaxPacketSize(n int) Option { return maxPacketSize(n) }
+
+// Transport implements gossip transport over UDP.
+type Transport struct {
+	conn     *net.UDPConn
+	resolver transport.Resolver
+	log      sladder.Logger
+
+	maxPacketSize int
+
+	readLock sync.Mutex
+	packet   []byte
+
+	closed uint32
+}
+
+// New binds UDP socket and creates transport.
+func New(bind string, options ...Option) (*Transport, error) {
+	t := &Transport{
+		resolver:      transport.AddressResolver{},
+		log:           sladder.DefaultLogger,
+		maxPacketSize: DefaultMaxPacketSize,
+	}
+	for _, option := range options {
+		switch v := option.(type) {
+		case resolver:
+			if v.Resolver != nil {
+				t.resolver = v.Resolver
+			}
+		case logger:
+			if v.Logger != nil {
+				t.log = v.Logger
+			}
+		case maxPacketSize:
+			size := int(v)
+			if size < 1 || size > MaxPacketSize {
+				size = MaxPacketSize
+			}
+			t.maxPacketSize = size
+		}
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", bind)
+	if err != nil {
+		return nil, err
+	}
+	if t.conn, err = net.ListenUDP("udp", addr); err != nil {
+		return nil, err
+	}
+	t.packet = make([]byte, t.maxPacketSize+1) // one more byte to detect oversize packet.
+
+	return t, nil
+}
+
+// LocalAddr returns bound address.
+func (t *Transport) LocalAddr() net.Addr { return t.conn.LocalAddr() }
+
+// MaxPacketSize returns packet size limitation.
+func (t *Transport) MaxPacketSize() int { return t.maxPacketSize }
+
+// Close closes underlying socket.
+func (t *Transport) Close() error {
+	if !atomic.CompareAndSwapUint32(&t.closed, 0, 1) {
+		return nil
+	}
+	return t.conn.Close()
+}
+
+func (t *Transport) isClosed() bool { return atomic.LoadUint32(&t.closed) != 0 }
+
+// Send sends packet to node.
+func (t *Transport) Send(names []string, buf []byte) {
+	if len(names) < 1 || t.isClosed() {
+		return
+	}
+	if len(buf) > t.maxPacketSize {
+		t.log.Warnf("udp transport drops an outgoing packet. (err = \"%v\") {size = %v, limit = %v, node = %v}", ErrPacketTooLarge, len(buf), t.maxPacketSize, names)
+		return
+	}
+
+	endpoint, err := t.resolver.Endpoint(names)
+	if err != nil {
+		t.log.Warnf("udp transport cannot resolve endpoint. (err = \"%v\") {node = %v}", err, names)
+		return
+	}
+	addr, err := net.ResolveUDPAddr("udp", endpoint)
+	if err != nil {
+		t.log.Warnf("udp transport cannot resolve endpoint. (err = \"%v\") {node = %v, endpoint = %v}", err, names, endpoint)
+		return
+	}
+	if _, err = t.conn.WriteToUDP(buf, addr); err != nil {
+		t.log.Warnf("udp transport failed to send packet. (err = \"%v\") {node = %v, endpoint = %v}", err, names, endpoint)
+	}
+}
+
+// Receive receives packet. It returns nil when the context is done.
+func (t *Transport) Receive(ctx context.Context) (from []string, buf []byte) {
+	t.readLock.Lock()
+	defer t.readLock.Unlock()
+
+	if t.isClosed() {
+		<-ctx.Done()
+		return nil, nil
+	}
+
+	// interrupt reading when context is done.
+	stop, watcherExited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(watcherExited)
+		select {
+		case <-ctx.Done():
+			t.conn.SetReadDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-watcherExited
+	}()
+
+	deadline, _ := ctx.Deadline()
+
+	for {
+		if err := t.conn.SetReadDeadline(deadline); err != nil {
+			if !t.isClosed() {
+				t.log.Warnf("udp transport cannot set read deadline. (err = \"%v\")", err)
+			}
+			return nil, nil
+		}
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+
+		n, addr, err := t.conn.ReadFromUDP(t.packet)
+		if err != nil {
+			if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+				return nil, nil
+			}
+			if !t.isClosed() {
+				t.log.Warnf("udp transport failed to receive packet. (err = \"%v\")", err)
+			}
+			return nil, nil
+		}
+		if n > t.maxPacketSize {
+			t.log.Warnf("udp transport drops an incoming packet. (err = \"%v\") {limit = %v, remote = %v}", ErrPacketTooLarge, t.maxPacketSize, addr)
+			continue
+		}
+
+		endpoint := addr.String()
+		if from = t.resolver.Names(endpoint); len(from) < 1 {
+			from = []string{endpoint}
+		}
+		buf = make([]byte, n)
+		copy(buf, t.packet[:n])
+
+		return from, buf
+	}
+}
@@ -0,0 +1,142 @@
+package udp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder"
+	"github.com/crossmesh/sladder/engine/gossip"
+	"github.com/crossmesh/sladder/engine/gossip/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ gossip.Transport = (*Transport)(nil)
+
+func newTestTransport(t *testing.T, options ...Option) *Transport {
+	tp, err := New("127.0.0.1:0", options...)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return tp
+}
+
+func TestUDPTransport(t *testing.T) {
+	t.Run("send_receive", func(t *testing.T) {
+		t1, t2 := newTestTransport(t), newTestTransport(t)
+		defer t1.Close()
+		defer t2.Close()
+
+		t1.Send([]string{"unknown", t2.LocalAddr().String()}, []byte("hello"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		from, buf := t2.Receive(ctx)
+		assert.Equal(t, []byte("hello"), buf)
+		assert.Equal(t, []string{t1.LocalAddr().String()}, from)
+	})
+
+	t.Run("static_resolver", func(t *testing.T) {
+		r := transport.NewStaticResolver()
+		t1, t2 := newTestTransport(t, WithResolver(r)), newTestTransport(t, WithResolver(r))
+		defer t1.Close()
+		defer t2.Close()
+		r.Bind(t1.LocalAddr().String(), "n1")
+		r.Bind(t2.LocalAddr().String(), "n2")
+
+		t1.Send([]string{"n2"}, []byte("hello"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		from, buf := t2.Receive(ctx)
+		assert.Equal(t, []byte("hello"), buf)
+		assert.Equal(t, []string{"n1"}, from)
+	})
+
+	t.Run("packet_size", func(t *testing.T) {
+		log := &sladder.MockLogger{}
+		log.On("Warnf", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+		t1, t2 := newTestTransport(t, WithLogger(log)), newTestTransport(t, WithMaxPacketSize(4))
+		defer t1.Close()
+		defer t2.Close()
+		assert.Equal(t, DefaultMaxPacketSize, t1.MaxPacketSize())
+		assert.Equal(t, 4, t2.MaxPacketSize())
+
+		t1.Send([]string{t2.LocalAddr().String()}, make([]byte, DefaultMaxPacketSize+1))
+		log.AssertNumberOfCalls(t, "Warnf", 1)
+
+		// oversize packet dropped by receiver.
+		t1.Send([]string{t2.LocalAddr().String()}, []byte("hello"))
+		t1.Send([]string{t2.LocalAddr().String()}, []byte("hi"))
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		_, buf := t2.Receive(ctx)
+		assert.Equal(t, []byte("hi"), buf)
+	})
+
+	t.Run("receive_context", func(t *testing.T) {
+		tp := newTestTransport(t)
+		defer tp.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		from, buf := tp.Receive(ctx)
+		assert.Nil(t, from)
+		assert.Nil(t, buf)
+
+		ctx, cancel = context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			cancel()
+		}()
+		from, buf = tp.Receive(ctx)
+		assert.Nil(t, from)
+		assert.Nil(t, buf)
+	})
+
+	t.Run("engine", func(t *testing.T) {
+		t1, t2 := newTestTransport(t), newTestTransport(t)
+		defer t1.Close()
+		defer t2.Close()
+
+		e1 := gossip.New(t1, gossip.ManualSync(), gossip.ManualFailureDetect(), gossip.ManualClearSuspections())
+		e2 := gossip.New(t2, gossip.ManualSync(), gossip.ManualFailureDetect(), gossip.ManualClearSuspections())
+		newCluster := func(e sladder.EngineInstance, name string) *sladder.Cluster {
+			c, _, err := sladder.NewClusterWithNameResolver(e, &sladder.TestNamesInKeyNameResolver{Key: "id"})
+			assert.NoError(t, err)
+			assert.NoError(t, c.RegisterKey("id", &sladder.TestNamesInKeyIDValidator{}, false, 0))
+			assert.NoError(t, c.Txn(func(tx *sladder.Transaction) bool {
+				rtx, err := tx.KV(c.Self(), "id")
+				if !assert.NoError(t, err) {
+					return false
+				}
+				rtx.(*sladder.TestNamesInKeyTxn).AddName(name)
+				return true
+			}))
+			return c
+		}
+		c1 := newCluster(e1, t1.LocalAddr().String())
+		c2 := newCluster(e2, t2.LocalAddr().String())
+
+		assert.NoError(t, c1.Txn(func(tx *sladder.Transaction) bool {
+			n, err := tx.NewNode()
+			if !assert.NoError(t, err) {
+				return false
+			}
+			rtx, err := tx.KV(n, "id")
+			if !assert.NoError(t, err) {
+				return false
+			}
+			rtx.(*sladder.TestNamesInKeyTxn).AddName(t2.LocalAddr().String())
+			return true
+		}, sladder.MembershipModification()))
+
+		e1.(*gossip.EngineInstance).ClusterSync()
+		for i := 0; i < 50 && c2.GetNode(t1.LocalAddr().String()) == nil; i++ {
+			time.Sleep(time.Millisecond * 100)
+		}
+		assert.NotNil(t, c2.GetNode(t1.LocalAddr().String()))
+	})
+}
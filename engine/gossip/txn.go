@@ -28,5 +28,9 @@ func (e *EngineInstance) TransactionCommit(t *sladder.Transaction, ops []*sladde
 		return
 	}
 
+	if accepted, err = e.traceLocalChanges(t, isEngineTxn, ops); !accepted || err != nil {
+		return
+	}
+
 	return true, nil
 }
@@ -0,0 +1,111 @@
+package sladder
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/crossmesh/sladder/proto"
+)
+
+// JSONEntry is entry in JSON snapshot.
+type JSONEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"` // real (unwrapped) value.
+	Raw   string `json:"raw"`   // raw value.
+}
+
+// JSONNode is node in JSON snapshot.
+type JSONNode struct {
+	Names   []string     `json:"names"`
+	Self    bool         `json:"self,omitempty"`
+	State   interface{}  `json:"state,omitempty"` // engine-specific states.
+	Entries []*JSONEntry `json:"entries"`
+}
+
+// JSONCluster is human-readable snapshot of cluster.
+type JSONCluster struct {
+	Nodes []*JSONNode `json:"nodes"`
+}
+
+// NodeStateDescriber describes engine-specific node states in JSON snapshot.
+type NodeStateDescriber interface {
+	DescribeNode(*Transaction, *Node) interface{}
+}
+
+// JSONSnapshot creates a JSON snapshot of cluster. Nodes are sorted by names and entries are sorted by keys.
+func (c *Cluster) JSONSnapshot() (s *JSONCluster, err error) {
+	s = &JSONCluster{}
+	describer, _ := c.engine.(NodeStateDescriber)
+
+	if err = c.Txn(func(t *Transaction) bool {
+		t.RangeNode(func(n *Node) bool {
+			var msg proto.Node
+			t.ReadNodeSnapshot(n, &msg)
+
+			node := &JSONNode{
+				Names: t.Names(n),
+				Self:  n == c.self,
+			}
+			for _, kv := range msg.Kvs {
+				real := c.getRealEntry(&KeyValue{Key: kv.Key, Value: kv.Value})
+				node.Entries = append(node.Entries, &JSONEntry{Key: kv.Key, Value: real.Value, Raw: kv.Value})
+			}
+			sort.Slice(node.Entries, func(i, j int) bool { return node.Entries[i].Key < node.Entries[j].Key })
+			if describer != nil {
+				node.State = describer.DescribeNode(t, n)
+			}
+			s.Nodes = append(s.Nodes, node)
+			return true
+		}, false, true)
+		return false
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(s.Nodes, func(i, j int) bool {
+		return strings.Join(s.Nodes[i].Names, ",") < strings.Join(s.Nodes[j].Names, ",")
+	})
+
+	return s, nil
+}
+
+// ProtobufSnapshot converts JSON snapshot to protobuf format with raw values.
+func (s *JSONCluster) ProtobufSnapshot(message *proto.Cluster) {
+	if message == nil {
+		return
+	}
+	message.Nodes = message.Nodes[:0]
+	for _, node := range s.Nodes {
+		msg := &proto.Node{}
+		for _, entry := range node.Entries {
+			msg.Kvs = append(msg.Kvs, &proto.Node_KeyValue{Key: entry.Key, Value: entry.Raw})
+		}
+		message.Nodes = append(message.Nodes, msg)
+	}
+}
+
+// ExportJSON writes JSON snapshot of cluster.
+func (c *Cluster) ExportJSON(w io.Writer) error {
+	s, err := c.JSONSnapshot()
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// ImportJSON reads JSON snapshot and applies it to cluster. Raw values are imported.
+func (c *Cluster) ImportJSON(r io.Reader, opts ...ApplySnapshotOption) error {
+	var (
+		s   JSONCluster
+		msg proto.Cluster
+	)
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return err
+	}
+	s.ProtobufSnapshot(&msg)
+	return c.ApplySnapshot(&msg, opts...)
+}
@@ -0,0 +1,41 @@
+package sladder
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/crossmesh/sladder/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSnapshot(t *testing.T) {
+	c := newTestSnapshotCluster(t)
+	assert.NoError(t, c.RegisterKey("id", &StringValidator{}, false, 0))
+	assert.NoError(t, c.RegisterKey("key1", &StringValidator{}, false, 0))
+	assert.NoError(t, c.ApplySnapshot(&proto.Cluster{Nodes: []*proto.Node{
+		{Kvs: []*proto.Node_KeyValue{{Key: "key1", Value: "b"}, {Key: "id", Value: "n2"}}},
+		{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: "n1"}, {Key: "key1", Value: "a"}}},
+	}}))
+
+	var buf bytes.Buffer
+	assert.NoError(t, c.ExportJSON(&buf))
+	var s JSONCluster
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &s))
+	assert.Equal(t, JSONCluster{Nodes: []*JSONNode{
+		{Names: []string{"n1"}, Entries: []*JSONEntry{{Key: "id", Value: "n1", Raw: "n1"}, {Key: "key1", Value: "a", Raw: "a"}}},
+		{Names: []string{"n2"}, Entries: []*JSONEntry{{Key: "id", Value: "n2", Raw: "n2"}, {Key: "key1", Value: "b", Raw: "b"}}},
+	}}, s)
+
+	// round-trip.
+	c2 := newTestSnapshotCluster(t)
+	assert.NoError(t, c2.RegisterKey("id", &StringValidator{}, false, 0))
+	assert.NoError(t, c2.RegisterKey("key1", &StringValidator{}, false, 0))
+	exported := buf.String()
+	assert.NoError(t, c2.ImportJSON(&buf, ReplaceSnapshot()))
+	buf.Reset()
+	assert.NoError(t, c2.ExportJSON(&buf))
+	assert.Equal(t, exported, buf.String())
+
+	assert.Error(t, c2.ImportJSON(bytes.NewBufferString("{")))
+}
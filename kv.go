@@ -71,6 +71,8 @@ type KeyValueEntry struct {
 const (
 	// LocalEntry will not be synced to remote.
 	LocalEntry = uint32(0x1)
+	// ReadOnlyRemoteEntry can be modified only by merging snapshot when it belongs to remote.
+	ReadOnlyRemoteEntry = uint32(0x2)
 )
 
 // KeyValue stores one metadata key of the node.
@@ -2,8 +2,10 @@ package sladder
 
 import (
 	"errors"
+	"sort"
 	"testing"
 
+	"github.com/crossmesh/sladder/proto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -83,3 +85,69 @@ func TestKeyValue(t *testing.T) {
 	assert.Equal(t, kv.Key, c.Key)
 	assert.Equal(t, kv.Value, c.Value)
 }
+
+func TestKeyFlags(t *testing.T) {
+	c := newTestSnapshotCluster(t)
+	self := c.Self()
+	assert.NoError(t, c.RegisterKey("id", &StringValidator{}, false, 0))
+	assert.NoError(t, c.RegisterKey("local", &StringValidator{}, false, LocalEntry))
+	assert.NoError(t, c.RegisterKey("ro", &StringValidator{}, false, ReadOnlyRemoteEntry))
+
+	snapshotKeys := func(n *Node) (keys []string) {
+		var msg proto.Node
+		n.ProtobufSnapshot(&msg)
+		for _, kv := range msg.Kvs {
+			keys = append(keys, kv.Key)
+		}
+		sort.Strings(keys)
+		return
+	}
+
+	t.Run("local", func(t *testing.T) {
+		assert.NoError(t, self._set("local", "1"))
+		assert.NoError(t, c.Keys("local", "id").Nodes(self).Set("self").AsError())
+		assert.Equal(t, []string{"id"}, snapshotKeys(self))
+		assert.Equal(t, "self", c.Keys("local").Get().Values[self]["local"])
+
+		// flags changed by registration.
+		assert.NoError(t, c.RegisterKey("local", &StringValidator{}, false, 0))
+		assert.Equal(t, []string{"id", "local"}, snapshotKeys(self))
+		assert.NoError(t, c.RegisterKey("local", &StringValidator{}, false, LocalEntry))
+		assert.Equal(t, []string{"id"}, snapshotKeys(self))
+	})
+
+	t.Run("read_only_remote", func(t *testing.T) {
+		assert.NoError(t, c.ApplySnapshot(&proto.Cluster{Nodes: []*proto.Node{
+			{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: "n1"}, {Key: "ro", Value: "a"}}},
+		}}))
+		n1 := c.GetNode("n1")
+		if !assert.NotNil(t, n1) {
+			t.FailNow()
+		}
+
+		err := c.Keys("ro").Nodes(n1).Set("b").Error
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), ErrTransactionCommitViolation.Error())
+		assert.Error(t, c.Keys("ro").Nodes(n1).Delete().Error)
+		assert.Error(t, c.Keys("ro").Nodes(n1).Set("b").Error)
+		assert.NoError(t, c.Keys("ro").Nodes(self).Set("b").AsError())
+
+		// merging is allowed.
+		assert.NoError(t, c.ApplySnapshot(&proto.Cluster{Nodes: []*proto.Node{
+			{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: "n1"}, {Key: "ro", Value: "c"}}},
+		}}))
+		assert.Equal(t, "c", c.Keys("ro").Nodes(n1).Get().Values[n1]["ro"])
+		assert.NoError(t, c.ApplySnapshot(&proto.Cluster{Nodes: []*proto.Node{
+			{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: "n1"}}},
+		}}, ReplaceSnapshot()))
+		assert.Equal(t, 0, len(c.Keys("ro").Nodes(n1).Get().Values))
+
+		// registry changes bypass the flag.
+		assert.NoError(t, c.ApplySnapshot(&proto.Cluster{Nodes: []*proto.Node{
+			{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: "n1"}, {Key: "ro", Value: "d"}}},
+		}}))
+		assert.Equal(t, "d", c.Keys("ro").Nodes(n1).Get().Values[n1]["ro"])
+		assert.NoError(t, c.RegisterKey("ro", nil, false, 0))
+		assert.Equal(t, 0, len(c.Keys("ro").Nodes(n1).Get().Values))
+	})
+}
@@ -100,11 +100,28 @@ func (n *Node) Delete(key string) (delete bool, err error) {
 	return err == nil, err
 }
 
+// CompareAndSwap sets real value of entry to new if the current one equals to old.
+// *ConflictError is returned if the current value differs.
+func (n *Node) CompareAndSwap(key, old, new string) error {
+	var errs Errors
+	if err := n.cluster.Txn(func(t *Transaction) bool {
+		if err := t.CompareAndSwap(n, key, old, new); err != nil {
+			errs = append(errs, err)
+			return false
+		}
+		return true
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs.AsError()
+}
+
 // Set sets KeyValue.
 func (n *Node) _set(key, value string) error {
 	// TODO(xutao): ensure consistency between entries and node names.
 	var entry *KeyValueEntry
-	var validator KVValidator
+	var model *keyModel
 
 	n.lock.Lock()
 
@@ -114,9 +131,9 @@ func (n *Node) _set(key, value string) error {
 		// that is: acquire cluster lock first, then acquire node lock.
 		n.lock.Unlock()
 		n.cluster.lock.RLock()
-		validator, exists = n.cluster.validators[key]
+		model = n.cluster.getKeyModel(key)
 		n.cluster.lock.RUnlock()
-		if !exists {
+		if model == nil {
 			return ErrValidatorMissing
 		}
 		// new KV.
@@ -125,9 +142,10 @@ func (n *Node) _set(key, value string) error {
 				Key:   key,
 				Value: value,
 			},
-			validator: validator,
+			flags:     model.flags,
+			validator: model.validator,
 		}
-		if !validator.Validate(newEntry.KeyValue) {
+		if !model.validator.Validate(newEntry.KeyValue) {
 			return ErrInvalidKeyValue
 		}
 
@@ -209,13 +227,13 @@ func (n *Node) get(key string) (entry *KeyValue) {
 	return &e.KeyValue
 }
 
-func deferReplaceValidator(t *Transaction, entry *KeyValueEntry, validator KVValidator) {
+func deferReplaceValidator(t *Transaction, entry *KeyValueEntry, validator KVValidator, flags uint32) {
 	t.DeferOnCommit(func() {
-		entry.validator = validator
+		entry.validator, entry.flags = validator, flags
 	})
 }
 
-func (n *Node) replaceValidator(t *Transaction, key string, validator KVValidator, forceReplace bool) error {
+func (n *Node) replaceValidator(t *Transaction, key string, validator KVValidator, flags uint32, forceReplace bool, migrate KeyMigration) error {
 	t.lockRelatedNode(n)
 
 	entry := n.getEntry(key)
@@ -224,19 +242,39 @@ func (n *Node) replaceValidator(t *Transaction, key string, validator KVValidato
 	}
 
 	if validator == nil {
-		t.Delete(n, key) // unregister model.
-	} else {
-		if !validator.Validate(entry.KeyValue) { // ensure that existing value is valid for new validator.
-			if !forceReplace {
-				return ErrIncompatibleValidator
+		return t.dropEntry(n, key) // unregister model.
+	}
+
+	if migrate != nil { // convert existing value to new format.
+		raw, err := migrate(entry.KeyValue)
+		if err == nil && !validator.Validate(KeyValue{Key: key, Value: raw}) {
+			err = ErrIncompatibleValidator
+		}
+		if err == nil {
+			if err = t.migrateEntry(n, key, validator, flags, raw); err != nil {
+				return err
 			}
+		} else if !forceReplace {
+			return err
+		} else {
+			// drop entry failing to migrate.
+			if err = t.dropEntry(n, key); err != nil {
+				return err
+			}
+		}
 
-			// drop entry in case of incompatiable validator.
-			t.Delete(n, key)
+	} else if !validator.Validate(entry.KeyValue) { // ensure that existing value is valid for new validator.
+		if !forceReplace {
+			return ErrIncompatibleValidator
 		}
 
-		deferReplaceValidator(t, entry, validator) // replace
+		// drop entry in case of incompatiable validator.
+		if err := t.dropEntry(n, key); err != nil {
+			return err
+		}
 	}
 
+	deferReplaceValidator(t, entry, validator, flags) // replace
+
 	return nil
 }
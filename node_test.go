@@ -2,6 +2,8 @@ package sladder
 
 import (
 	"fmt"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/crossmesh/sladder/proto"
@@ -104,3 +106,49 @@ func TestNode(t *testing.T) {
 		}
 	})
 }
+
+func TestNodeCompareAndSwap(t *testing.T) {
+	c, self, err := newTestFakedCluster(&TestRandomNameResolver{}, nil, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, c.RegisterKey("key1", &StringValidator{}, false, 0))
+
+	// missing entry.
+	err = self.CompareAndSwap("key1", "1", "2")
+	if assert.IsType(t, &ConflictError{}, err) {
+		assert.False(t, err.(*ConflictError).Exists)
+	}
+	assert.NoError(t, self.CompareAndSwap("key1", "", "0"))
+
+	err = self.CompareAndSwap("key1", "1", "2")
+	if assert.IsType(t, &ConflictError{}, err) {
+		conflict := err.(*ConflictError)
+		assert.True(t, conflict.Exists)
+		assert.Equal(t, "0", conflict.Current)
+		assert.Equal(t, "1", conflict.Expected)
+	}
+	assert.Equal(t, ErrValidatorMissing, self.CompareAndSwap("key2", "", "1"))
+
+	// concurrent read-modify-write.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 50; {
+				value, _ := self.Keys("key1").Get().Value(self, "key1")
+				current, _ := strconv.Atoi(value)
+				err := self.CompareAndSwap("key1", value, strconv.Itoa(current+1))
+				if _, conflict := err.(*ConflictError); conflict {
+					continue
+				}
+				assert.NoError(t, err)
+				n++
+			}
+		}()
+	}
+	wg.Wait()
+	value, _ := self.Keys("key1").Get().Value(self, "key1")
+	assert.Equal(t, "400", value)
+}
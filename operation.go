@@ -1,5 +1,14 @@
 package sladder
 
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoKeySelected = errors.New("no key selected")
+)
+
 // OperationContext traces operation scope.
 type OperationContext struct {
 	keys      []string
@@ -73,3 +82,214 @@ func (c *OperationContext) Watch(handler WatchEventHandler) *WatchEventContext {
 
 	return c.cluster.eventRegistry.watchKV(c.clone(), handler)
 }
+
+// OperationResult contains per-node and per-key results of operation.
+type OperationResult struct {
+	Values map[*Node]map[string]string // real values.
+	Errors map[*Node]map[string]error  // entry errors.
+
+	Error error // transaction error.
+}
+
+func newOperationResult() *OperationResult {
+	return &OperationResult{
+		Values: make(map[*Node]map[string]string),
+		Errors: make(map[*Node]map[string]error),
+	}
+}
+
+func (r *OperationResult) setValue(node *Node, key, value string) {
+	values, _ := r.Values[node]
+	if values == nil {
+		values = make(map[string]string)
+		r.Values[node] = values
+	}
+	values[key] = value
+}
+
+func (r *OperationResult) setError(node *Node, key string, err error) {
+	errs, _ := r.Errors[node]
+	if errs == nil {
+		errs = make(map[string]error)
+		r.Errors[node] = errs
+	}
+	errs[key] = err
+}
+
+// Value returns real value of entry.
+func (r *OperationResult) Value(node *Node, key string) (value string, exists bool) {
+	value, exists = r.Values[node][key]
+	return
+}
+
+// AsError presents all errors as a normal error.
+func (r *OperationResult) AsError() error {
+	var errs Errors
+	for node, keyErrs := range r.Errors {
+		for key, err := range keyErrs {
+			errs = append(errs, fmt.Errorf("%v {node = %v, key = %v}", err, node.PrintableName(), key))
+		}
+	}
+	errs.Trace(r.Error)
+	return errs.AsError()
+}
+
+// selectedNodes returns selected nodes in transaction. All nodes are selected if no node is specified.
+// Unknown nodes are ignored.
+func (c *OperationContext) selectedNodes(t *Transaction) (nodes []*Node) {
+	if len(c.nodes)+len(c.nodeNames) < 1 {
+		t.RangeNode(func(n *Node) bool {
+			nodes = append(nodes, n)
+			return true
+		}, false, false)
+		return
+	}
+
+	selected := make(map[*Node]struct{}, len(c.nodes)+len(c.nodeNames))
+	for node := range c.nodes {
+		if t.Cluster.containNodes(node) {
+			selected[node] = struct{}{}
+		}
+	}
+	for _, name := range c.nodeNames {
+		if node := t.Cluster.getNode(name); node != nil {
+			selected[node] = struct{}{}
+		}
+	}
+	for node := range selected {
+		nodes = append(nodes, node)
+	}
+	return
+}
+
+// selectedKeys returns selected keys of node. All existing keys are selected if no key is specified.
+func (c *OperationContext) selectedKeys(t *Transaction, node *Node, existingOnly bool) (keys []string) {
+	if len(c.keys) < 1 {
+		t.RangeNodeKeys(node, func(key string, pastExists bool) bool {
+			keys = append(keys, key)
+			return true
+		})
+		return
+	}
+	for _, key := range c.keys {
+		if !existingOnly || t.KeyExists(node, key) {
+			keys = append(keys, key)
+		}
+	}
+	return
+}
+
+// Range iterates over selected entries with real values in a transaction.
+// visit should not start another transaction.
+func (c *OperationContext) Range(visit func(node *Node, key, value string) bool) error {
+	var errs Errors
+
+	if err := c.cluster.Txn(func(t *Transaction) bool {
+		for _, node := range c.selectedNodes(t) {
+			for _, key := range c.selectedKeys(t, node, true) {
+				rtx, err := t.KV(node, key)
+				if err != nil {
+					errs = append(errs, err)
+					return false
+				}
+				if !visit(node, key, rtx.After()) {
+					return false
+				}
+			}
+		}
+		return false
+	}); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs.AsError()
+}
+
+// Get reads real values of selected entries in a transaction.
+func (c *OperationContext) Get() *OperationResult {
+	r := newOperationResult()
+
+	r.Error = c.cluster.Txn(func(t *Transaction) bool {
+		for _, node := range c.selectedNodes(t) {
+			for _, key := range c.selectedKeys(t, node, true) {
+				rtx, err := t.KV(node, key)
+				if err != nil {
+					r.setError(node, key, err)
+					continue
+				}
+				r.setValue(node, key, rtx.After())
+			}
+		}
+		return false
+	})
+
+	return r
+}
+
+// Set sets real value of selected entries in a transaction. Keys must be specified.
+// Nothing is changed if any of entries fails.
+func (c *OperationContext) Set(value string) *OperationResult {
+	r := newOperationResult()
+	if len(c.keys) < 1 {
+		r.Error = ErrNoKeySelected
+		return r
+	}
+
+	r.Error = c.cluster.Txn(func(t *Transaction) bool {
+		failed := false
+		for _, node := range c.selectedNodes(t) {
+			for _, key := range c.selectedKeys(t, node, false) {
+				rtx, err := t.KV(node, key)
+				if err == nil {
+					err = rtx.SetRawValue(value)
+				}
+				if err != nil {
+					r.setError(node, key, err)
+					failed = true
+					continue
+				}
+				r.setValue(node, key, rtx.After())
+			}
+		}
+		return !failed
+	})
+	if r.Error != nil || len(r.Errors) > 0 {
+		r.Values = make(map[*Node]map[string]string)
+	}
+
+	return r
+}
+
+// Delete removes selected entries in a transaction. Keys must be specified. Values of removed entries are returned.
+// Nothing is changed if any of entries fails.
+func (c *OperationContext) Delete() *OperationResult {
+	r := newOperationResult()
+	if len(c.keys) < 1 {
+		r.Error = ErrNoKeySelected
+		return r
+	}
+
+	r.Error = c.cluster.Txn(func(t *Transaction) bool {
+		failed := false
+		for _, node := range c.selectedNodes(t) {
+			for _, key := range c.selectedKeys(t, node, true) {
+				rtx, err := t.KV(node, key)
+				if err == nil {
+					value := rtx.Before()
+					if err = t.Delete(node, key); err == nil {
+						r.setValue(node, key, value)
+						continue
+					}
+				}
+				r.setError(node, key, err)
+				failed = true
+			}
+		}
+		return !failed
+	})
+	if r.Error != nil || len(r.Errors) > 0 {
+		r.Values = make(map[*Node]map[string]string)
+	}
+
+	return r
+}
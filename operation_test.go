@@ -3,6 +3,7 @@ package sladder
 import (
 	"testing"
 
+	"github.com/crossmesh/sladder/proto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -155,3 +156,84 @@ func TestOperation(t *testing.T) {
 	})
 
 }
+
+func TestOperationReadWrite(t *testing.T) {
+	c := newTestSnapshotCluster(t)
+	assert.NoError(t, c.RegisterKey("id", &StringValidator{}, false, 0))
+	assert.NoError(t, c.RegisterKey("key1", &StringValidator{}, false, 0))
+	assert.NoError(t, c.RegisterKey("key2", &StringValidator{}, false, 0))
+	assert.NoError(t, c.ApplySnapshot(&proto.Cluster{Nodes: []*proto.Node{
+		{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: "n1"}, {Key: "key1", Value: "a"}}},
+		{Kvs: []*proto.Node_KeyValue{{Key: "id", Value: "n2"}, {Key: "key1", Value: "b"}, {Key: "key2", Value: "c"}}},
+	}}))
+	n1, n2 := c.GetNode("n1"), c.GetNode("n2")
+	if !assert.NotNil(t, n1) || !assert.NotNil(t, n2) {
+		t.FailNow()
+	}
+
+	t.Run("get", func(t *testing.T) {
+		r := c.Keys("key1", "key2").Nodes("n1", "n2", "n3").Get()
+		assert.NoError(t, r.AsError())
+		assert.Equal(t, map[*Node]map[string]string{
+			n1: {"key1": "a"},
+			n2: {"key1": "b", "key2": "c"},
+		}, r.Values)
+
+		r = c.Nodes(n2).Get()
+		assert.NoError(t, r.AsError())
+		assert.Equal(t, map[string]string{"id": "n2", "key1": "b", "key2": "c"}, r.Values[n2])
+
+		r = c.Keys("key3").Get()
+		assert.NoError(t, r.AsError())
+		assert.Equal(t, 0, len(r.Values))
+	})
+
+	t.Run("range", func(t *testing.T) {
+		visited := map[string]string{}
+		assert.NoError(t, n2.Keys("key1", "key2").Range(func(node *Node, key, value string) bool {
+			assert.Equal(t, n2, node)
+			visited[key] = value
+			return true
+		}))
+		assert.Equal(t, map[string]string{"key1": "b", "key2": "c"}, visited)
+
+		count := 0
+		assert.NoError(t, c.Keys("key1").Range(func(node *Node, key, value string) bool {
+			count++
+			return false
+		}))
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("set", func(t *testing.T) {
+		r := c.Keys("key2").Nodes(n1, n2).Set("d")
+		assert.NoError(t, r.AsError())
+		value, exists := c.Keys("key2").Nodes(n1).Get().Value(n1, "key2")
+		assert.True(t, exists)
+		assert.Equal(t, "d", value)
+
+		r = c.Nodes(n1).Set("x")
+		assert.Equal(t, ErrNoKeySelected, r.Error)
+
+		// atomic.
+		r = c.Keys("key1", "key3").Nodes(n1).Set("e")
+		assert.Error(t, r.AsError())
+		assert.Equal(t, ErrValidatorMissing, r.Errors[n1]["key3"])
+		assert.Equal(t, 0, len(r.Values))
+		value, _ = c.Keys("key1").Get().Value(n1, "key1")
+		assert.Equal(t, "a", value)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		r := c.Nodes(n1).Delete()
+		assert.Equal(t, ErrNoKeySelected, r.Error)
+		value, exists := c.Keys("key1").Get().Value(n1, "key1")
+		assert.True(t, exists)
+		assert.Equal(t, "a", value)
+
+		r = c.Keys("key2").Delete()
+		assert.NoError(t, r.AsError())
+		assert.Equal(t, map[*Node]map[string]string{n1: {"key2": "d"}, n2: {"key2": "d"}}, r.Values)
+		assert.Equal(t, 0, len(c.Keys("key2").Get().Values))
+	})
+}
@@ -0,0 +1,254 @@
+package sladder
+
+import (
+	"errors"
+	"path"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidKeyPattern = errors.New("invalid key pattern")
+)
+
+// keyModel contains registration of key.
+type keyModel struct {
+	validator KVValidator
+	flags     uint32
+}
+
+func (m *keyModel) getValidator() KVValidator {
+	if m == nil {
+		return nil
+	}
+	return m.validator
+}
+
+func (m *keyModel) getFlags() uint32 {
+	if m == nil {
+		return 0
+	}
+	return m.flags
+}
+
+// keyPattern registers model for keys matching prefix or glob.
+type keyPattern struct {
+	pattern string
+	prefix  bool
+	model   *keyModel
+}
+
+// literalPrefix returns the leading part of pattern matching literally.
+func (p *keyPattern) literalPrefix() string {
+	if p.prefix {
+		return p.pattern
+	}
+	if idx := strings.IndexAny(p.pattern, `*?[\`); idx >= 0 {
+		return p.pattern[:idx]
+	}
+	return p.pattern
+}
+
+func (p *keyPattern) match(key string) bool {
+	if p.prefix {
+		return strings.HasPrefix(key, p.pattern)
+	}
+	matched, _ := path.Match(p.pattern, key)
+	return matched
+}
+
+// keyRegistry resolves models of keys.
+// Exact registration is preferred, then matching pattern with the longest literal prefix, then the default model.
+type keyRegistry struct {
+	exact    map[string]*keyModel
+	patterns []*keyPattern // ordered by priority.
+	fallback *keyModel
+}
+
+func newKeyRegistry() *keyRegistry {
+	return &keyRegistry{exact: make(map[string]*keyModel)}
+}
+
+func (r *keyRegistry) clone() *keyRegistry {
+	new := &keyRegistry{
+		exact:    make(map[string]*keyModel, len(r.exact)),
+		patterns: append([]*keyPattern(nil), r.patterns...),
+		fallback: r.fallback,
+	}
+	for key, model := range r.exact {
+		new.exact[key] = model
+	}
+	return new
+}
+
+func (r *keyRegistry) get(key string) *keyModel {
+	if model, _ := r.exact[key]; model != nil {
+		return model
+	}
+	for _, p := range r.patterns {
+		if p.match(key) {
+			return p.model
+		}
+	}
+	return r.fallback
+}
+
+func (r *keyRegistry) setExact(key string, model *keyModel) {
+	if model == nil {
+		delete(r.exact, key)
+		return
+	}
+	r.exact[key] = model
+}
+
+func (r *keyRegistry) setPattern(pattern string, prefix bool, model *keyModel) {
+	for idx, p := range r.patterns {
+		if p.pattern == pattern && p.prefix == prefix {
+			r.patterns = append(r.patterns[:idx:idx], r.patterns[idx+1:]...)
+			break
+		}
+	}
+	if model == nil {
+		return
+	}
+	r.patterns = append(r.patterns, &keyPattern{pattern: pattern, prefix: prefix, model: model})
+	sort.SliceStable(r.patterns, func(i, j int) bool {
+		pi, pj := r.patterns[i], r.patterns[j]
+		if li, lj := len(pi.literalPrefix()), len(pj.literalPrefix()); li != lj {
+			return li > lj // longest literal prefix.
+		}
+		if pi.prefix != pj.prefix {
+			return !pi.prefix // glob is more specific.
+		}
+		if len(pi.pattern) != len(pj.pattern) {
+			return len(pi.pattern) > len(pj.pattern)
+		}
+		return pi.pattern < pj.pattern
+	})
+}
+
+func (c *Cluster) getKeyModel(key string) *keyModel { return c.registry.get(key) }
+
+// KeyMigration converts existing raw value into raw value in format of the new validator.
+type KeyMigration func(old KeyValue) (string, error)
+
+// RegisterKeyOption contains extra requirements for registering key.
+type RegisterKeyOption interface{}
+
+type keyMigrationOption KeyMigration
+
+// WithKeyMigration creates an option to convert existing entries when validator is replaced.
+// Conversions are done in the registering transaction, emitting ValueChanged events.
+// Registration fails if any conversion fails, unless forceReplace is set, in which case the failed entries are dropped.
+func WithKeyMigration(migrate KeyMigration) RegisterKeyOption { return keyMigrationOption(migrate) }
+
+func newKeyModel(validator KVValidator, flags uint32) *keyModel {
+	if validator == nil {
+		return nil
+	}
+	return &keyModel{validator: validator, flags: flags}
+}
+
+// updateKeyRegistry updates key registration and applies the new models to existing entries.
+// Entries are removed if their keys are no longer covered, and converted by migration if given.
+func (c *Cluster) updateKeyRegistry(update func(*keyRegistry), forceReplace bool, opts []RegisterKeyOption) error {
+	var (
+		errs    Errors
+		migrate KeyMigration
+	)
+
+	for _, opt := range opts {
+		switch v := opt.(type) {
+		case keyMigrationOption:
+			migrate = KeyMigration(v)
+		}
+	}
+
+	if err := c.Txn(func(t *Transaction) bool {
+		registry := c.registry.clone()
+		update(registry)
+
+		nodes := make([]*Node, 0)
+		t.RangeNode(func(node *Node) bool {
+			nodes = append(nodes, node)
+			return true
+		}, false, false)
+
+		for _, node := range nodes {
+			var keys []string
+			t.RangeNodeKeys(node, func(key string, pastExists bool) bool {
+				keys = append(keys, key)
+				return true
+			})
+			for _, key := range keys {
+				model := registry.get(key)
+				if model == c.registry.get(key) {
+					continue
+				}
+				// replace validator.
+				if err := node.replaceValidator(t, key, model.getValidator(), model.getFlags(), forceReplace, migrate); err != nil {
+					errs = append(errs, err)
+					return false
+				}
+			}
+		}
+
+		// assign the new
+		t.DeferOnCommit(func() {
+			c.registry = registry
+		})
+
+		return true
+	}, MembershipModification()); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := errs.AsError(); err != nil {
+		return err
+	}
+
+	// snapshot may be waiting for the key.
+	c.tryRestoreSnapshot()
+
+	return nil
+}
+
+// RegisterKey registers key-value validator with specific key.
+// flags are applied to all entries of the key. Registering nil validator unregisters the key.
+func (c *Cluster) RegisterKey(key string, validator KVValidator, forceReplace bool, flags uint32, opts ...RegisterKeyOption) error {
+	return c.updateKeyRegistry(func(r *keyRegistry) {
+		r.setExact(key, newKeyModel(validator, flags))
+	}, forceReplace, opts)
+}
+
+// RegisterKeyPrefix registers key-value validator for keys with the prefix.
+// Registering nil validator unregisters the prefix.
+//
+// Exact registration by RegisterKey always takes priority. Among matching prefixes and patterns, the one with the
+// longest literal prefix wins. The literal prefix of a prefix registration is the prefix itself, and that of a glob
+// pattern is the part before its first special character. Ties are broken by preferring glob patterns, then longer
+// patterns, then lexical order.
+func (c *Cluster) RegisterKeyPrefix(prefix string, validator KVValidator, forceReplace bool, flags uint32, opts ...RegisterKeyOption) error {
+	return c.updateKeyRegistry(func(r *keyRegistry) {
+		r.setPattern(prefix, true, newKeyModel(validator, flags))
+	}, forceReplace, opts)
+}
+
+// RegisterKeyPattern registers key-value validator for keys matching glob pattern in syntax of path.Match.
+// Registering nil validator unregisters the pattern. Priority among patterns follows RegisterKeyPrefix.
+func (c *Cluster) RegisterKeyPattern(pattern string, validator KVValidator, forceReplace bool, flags uint32, opts ...RegisterKeyOption) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return ErrInvalidKeyPattern
+	}
+	return c.updateKeyRegistry(func(r *keyRegistry) {
+		r.setPattern(pattern, false, newKeyModel(validator, flags))
+	}, forceReplace, opts)
+}
+
+// RegisterDefaultKey registers key-value validator for keys not covered by any registration.
+// Registering nil validator unregisters the default.
+func (c *Cluster) RegisterDefaultKey(validator KVValidator, forceReplace bool, flags uint32, opts ...RegisterKeyOption) error {
+	return c.updateKeyRegistry(func(r *keyRegistry) {
+		r.fallback = newKeyModel(validator, flags)
+	}, forceReplace, opts)
+}
@@ -0,0 +1,183 @@
+package sladder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type namedValidator struct {
+	StringValidator
+	name string
+}
+
+func TestKeyRegistry(t *testing.T) {
+	exact, prefix, long := &namedValidator{name: "exact"}, &namedValidator{name: "prefix"}, &namedValidator{name: "long"}
+	glob, fallback := &namedValidator{name: "glob"}, &namedValidator{name: "fallback"}
+
+	c := newTestSnapshotCluster(t)
+	assert.NoError(t, c.RegisterKey("id", &StringValidator{}, false, 0))
+	assert.NoError(t, c.RegisterKey("app.name", exact, false, 0))
+	assert.NoError(t, c.RegisterKeyPrefix("app.", prefix, false, 0))
+	assert.NoError(t, c.RegisterKeyPrefix("app.meta.", long, false, LocalEntry))
+	assert.NoError(t, c.RegisterKeyPattern("app.*.port", glob, false, 0))
+	assert.Equal(t, ErrInvalidKeyPattern, c.RegisterKeyPattern("app.[", glob, false, 0))
+
+	selfEntries := func() map[string]string {
+		m := make(map[string]string)
+		for _, kv := range c.Self().KeyValueEntries(true) {
+			m[kv.Key] = kv.Value
+		}
+		return m
+	}
+	validatorOf := func(key string) KVValidator { return c.getKeyModel(key).getValidator() }
+	assert.Equal(t, KVValidator(exact), validatorOf("app.name"))
+	assert.Equal(t, KVValidator(prefix), validatorOf("app.version"))
+	assert.Equal(t, KVValidator(long), validatorOf("app.meta.owner"))
+	assert.Equal(t, KVValidator(glob), validatorOf("app.http.port"))
+	assert.Nil(t, validatorOf("other"))
+	assert.Equal(t, LocalEntry, c.getKeyModel("app.meta.owner").getFlags())
+
+	assert.NoError(t, c.Txn(func(tx *Transaction) bool {
+		_, err := tx.KV(c.Self(), "other")
+		assert.Equal(t, ErrValidatorMissing, err)
+		return false
+	}))
+
+	t.Run("priority", func(t *testing.T) {
+		r := newKeyRegistry()
+		short, wild := &keyModel{validator: prefix}, &keyModel{validator: glob}
+		r.setPattern("*.port", false, wild)
+		r.setPattern("a*", false, short)
+		assert.True(t, r.get("a.port") == short) // longer literal prefix wins.
+		assert.True(t, r.get("b.port") == wild)
+
+		r.setPattern("a", true, &keyModel{validator: long})
+		assert.True(t, r.get("a.port") == short) // glob wins the tie.
+	})
+
+	t.Run("default", func(t *testing.T) {
+		assert.NoError(t, c.RegisterDefaultKey(fallback, false, 0))
+		assert.Equal(t, KVValidator(fallback), validatorOf("other"))
+		assert.Equal(t, KVValidator(prefix), validatorOf("app.version"))
+
+		assert.NoError(t, c.Txn(func(tx *Transaction) bool {
+			for _, key := range []string{"other", "app.version", "app.meta.owner"} {
+				rtx, err := tx.KV(c.Self(), key)
+				if !assert.NoError(t, err) {
+					return false
+				}
+				rtx.(*StringTxn).Set("v")
+			}
+			return true
+		}))
+	})
+
+	t.Run("unregister", func(t *testing.T) {
+		// entries fall back to the next matching registration.
+		assert.NoError(t, c.RegisterKeyPrefix("app.meta.", nil, false, 0))
+		assert.Equal(t, KVValidator(prefix), validatorOf("app.meta.owner"))
+		assert.Equal(t, uint32(0), c.getKeyModel("app.meta.owner").getFlags())
+		assert.Equal(t, "v", selfEntries()["app.meta.owner"])
+
+		// entries are removed once no longer covered.
+		assert.NoError(t, c.RegisterDefaultKey(nil, false, 0))
+		assert.Nil(t, validatorOf("other"))
+		assert.NotContains(t, selfEntries(), "other")
+		assert.Equal(t, "v", selfEntries()["app.version"])
+
+		assert.NoError(t, c.RegisterKeyPrefix("app.", nil, false, 0))
+		assert.NotContains(t, selfEntries(), "app.version")
+		assert.Equal(t, KVValidator(exact), validatorOf("app.name"))
+	})
+}
+
+type v2Validator struct{ StringValidator }
+
+func (v v2Validator) Validate(kv KeyValue) bool { return strings.HasPrefix(kv.Value, "v2:") }
+
+func TestKeyMigration(t *testing.T) {
+	setup := func(t *testing.T) *Cluster {
+		c := newTestSnapshotCluster(t)
+		assert.NoError(t, c.RegisterKey("id", &StringValidator{}, false, 0))
+		assert.NoError(t, c.RegisterKey("key1", &StringValidator{}, false, 0))
+		for _, name := range []string{"n1", "n2"} {
+			n, err := c.NewNode()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.NoError(t, c.Txn(func(tx *Transaction) bool {
+				for key, value := range map[string]string{"id": name, "key1": name} {
+					rtx, err := tx.KV(n, key)
+					if !assert.NoError(t, err) {
+						return false
+					}
+					rtx.(*StringTxn).Set(value)
+				}
+				return true
+			}))
+		}
+		return c
+	}
+	valueOf := func(c *Cluster, name string) (string, bool) {
+		for _, kv := range c.GetNode(name).KeyValueEntries(true) {
+			if kv.Key == "key1" {
+				return kv.Value, true
+			}
+		}
+		return "", false
+	}
+
+	t.Run("migrate", func(t *testing.T) {
+		c := setup(t)
+
+		changes := make(map[string]string)
+		c.Keys("key1").Watch(func(ctx *WatchEventContext, meta KeyValueEventMetadata) {
+			if meta.Event() != ValueChanged {
+				return
+			}
+			m := meta.(KeyChangeEventMetadata)
+			changes[m.Old()] = m.New()
+		})
+
+		assert.Equal(t, ErrIncompatibleValidator, c.RegisterKey("key1", v2Validator{}, false, 0))
+		assert.NoError(t, c.RegisterKey("key1", v2Validator{}, false, 0, WithKeyMigration(func(kv KeyValue) (string, error) {
+			return "v2:" + kv.Value, nil
+		})))
+		c.EventBarrier()
+
+		for _, name := range []string{"n1", "n2"} {
+			value, exists := valueOf(c, name)
+			assert.True(t, exists)
+			assert.Equal(t, "v2:"+name, value)
+		}
+		assert.Equal(t, map[string]string{"n1": "v2:n1", "n2": "v2:n2"}, changes)
+		assert.Equal(t, KVValidator(v2Validator{}), c.GetNode("n1").getEntry("key1").validator)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		errBad := errors.New("cannot migrate")
+		migrate := WithKeyMigration(func(kv KeyValue) (string, error) {
+			if kv.Value == "n2" {
+				return "", errBad
+			}
+			return "v2:" + kv.Value, nil
+		})
+
+		// whole registration is rejected.
+		c := setup(t)
+		assert.Equal(t, errBad, c.RegisterKey("key1", v2Validator{}, false, 0, migrate))
+		value, _ := valueOf(c, "n1")
+		assert.Equal(t, "n1", value)
+		assert.Equal(t, KVValidator(&StringValidator{}), c.getKeyModel("key1").getValidator())
+
+		// failed entries are dropped.
+		assert.NoError(t, c.RegisterKey("key1", v2Validator{}, true, 0, migrate))
+		value, _ = valueOf(c, "n1")
+		assert.Equal(t, "v2:n1", value)
+		_, exists := valueOf(c, "n2")
+		assert.False(t, exists)
+	})
+}
@@ -0,0 +1,378 @@
+package sladder
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/crossmesh/sladder/proto"
+	"github.com/crossmesh/sladder/util"
+	gproto "google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrUnresolvableNodeSnapshot = errors.New("cannot resolve names of node snapshot")
+)
+
+const defaultSnapshotInterval = time.Second * 30
+
+// SnapshotStore persists cluster snapshot across restarts.
+type SnapshotStore interface {
+	// Load returns the last saved snapshot. nil is returned if nothing saved.
+	Load() (*proto.Snapshot, error)
+	// Save saves snapshot.
+	Save(*proto.Snapshot) error
+}
+
+// FileSnapshotStore stores snapshot in file.
+type FileSnapshotStore string
+
+// Load reads snapshot from file. Missing file is treated as nothing saved.
+func (f FileSnapshotStore) Load() (*proto.Snapshot, error) {
+	raw, err := ioutil.ReadFile(string(f))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	s := &proto.Snapshot{}
+	if err = gproto.Unmarshal(raw, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save writes snapshot to file atomically.
+func (f FileSnapshotStore) Save(s *proto.Snapshot) error {
+	raw, err := gproto.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(string(f), raw, 0600)
+}
+
+type snapshotStoreOption struct{ SnapshotStore }
+
+// WithSnapshotStore is option to persist cluster snapshot periodically and restore it on startup.
+func WithSnapshotStore(s SnapshotStore) ClusterOption { return snapshotStoreOption{s} }
+
+type snapshotIntervalOption time.Duration
+
+// WithSnapshotInterval is option to specify interval of saving snapshot. Zero disables periodical saving.
+func WithSnapshotInterval(d time.Duration) ClusterOption { return snapshotIntervalOption(d) }
+
+// PersistentSnapshot creates a snapshot of cluster for persistence.
+// Myself is excluded since local states should be established by the new life.
+// Keys of all persisted entries are recorded, whatever registrations cover them, so that restoration waits for them.
+func (c *Cluster) PersistentSnapshot(s *proto.Snapshot) {
+	if s == nil {
+		return
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var (
+		cs    proto.Cluster
+		names [][]string
+	)
+	c.protobufSnapshot(&cs, func(n *Node) bool {
+		if n == c.self {
+			return false
+		}
+		names = append(names, n.Names())
+		return true
+	})
+
+	s.Nodes, s.Keys = s.Nodes[:0], s.Keys[:0]
+	keys := make(map[string]struct{})
+	for idx, node := range cs.Nodes {
+		s.Nodes = append(s.Nodes, &proto.Snapshot_Node{Names: names[idx], Node: node})
+		for _, kv := range node.Kvs {
+			if _, dup := keys[kv.Key]; !dup {
+				keys[kv.Key] = struct{}{}
+				s.Keys = append(s.Keys, kv.Key)
+			}
+		}
+	}
+	sort.Strings(s.Keys)
+	s.Timestamp = time.Now().UnixNano()
+}
+
+// SaveSnapshot saves snapshot to snapshot store.
+// Provisional view restored from snapshot will not be saved until the cluster is synced.
+func (c *Cluster) SaveSnapshot() error {
+	if c.snapshotStore == nil || c.Stale() {
+		return nil
+	}
+	s := &proto.Snapshot{}
+	c.PersistentSnapshot(s)
+	return c.snapshotStore.Save(s)
+}
+
+// Stale reports whether the cluster view is a provisional one restored from snapshot.
+func (c *Cluster) Stale() bool { return atomic.LoadUint32(&c.stale) != 0 }
+
+// Synced marks the cluster view synchronized with others. Engines call it after a successful sync.
+func (c *Cluster) Synced() {
+	if atomic.LoadUint32(&c.stale) == 0 {
+		return
+	}
+
+	c.snapshotLock.Lock()
+	defer c.snapshotLock.Unlock()
+
+	c.pendingSnapshot = nil // live states are preferred.
+	atomic.StoreUint32(&c.stale, 0)
+}
+
+func (c *Cluster) loadSnapshot() {
+	s, err := c.snapshotStore.Load()
+	if err != nil {
+		c.log.Warnf("failed to load snapshot. start with empty view. (err = \"%v\")", err)
+		return
+	}
+	if s == nil {
+		return
+	}
+	c.pendingSnapshot = s
+	atomic.StoreUint32(&c.stale, 1)
+}
+
+// tryRestoreSnapshot restores the loaded snapshot once all keys in snapshot are registered.
+func (c *Cluster) tryRestoreSnapshot() {
+	c.snapshotLock.Lock()
+	defer c.snapshotLock.Unlock()
+
+	s := c.pendingSnapshot
+	if s == nil {
+		return
+	}
+
+	c.lock.RLock()
+	for _, key := range s.Keys {
+		if c.getKeyModel(key) == nil {
+			c.lock.RUnlock()
+			return
+		}
+	}
+	c.lock.RUnlock()
+
+	c.pendingSnapshot = nil
+	if err := c.restoreSnapshot(s); err != nil {
+		c.log.Warnf("failed to restore snapshot. (err = \"%v\")", err)
+	}
+}
+
+func (c *Cluster) restoreSnapshot(s *proto.Snapshot) error {
+	var errs Errors
+
+	if err := c.Txn(func(t *Transaction) bool {
+		for _, sn := range s.Nodes {
+			if sn.Node == nil || len(sn.Names) < 1 {
+				continue
+			}
+			if t.MostPossibleNode(sn.Names) != nil {
+				continue // known node, including myself.
+			}
+			node, err := t.NewNode()
+			if err != nil {
+				errs = append(errs, err)
+				return false
+			}
+			if err = t.MergeNodeSnapshot(node, sn.Node, false, false, false); err != nil {
+				errs = append(errs, err)
+				return false
+			}
+		}
+		return true
+	}, MembershipModification()); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs.AsError()
+}
+
+func (c *Cluster) startSnapshotWorker() {
+	c.arbiter.Go(func() {
+		ticker := time.NewTicker(c.snapshotInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-c.arbiter.Exit():
+				return
+			}
+			if err := c.SaveSnapshot(); err != nil {
+				c.log.Warnf("failed to save snapshot. (err = \"%v\")", err)
+			}
+		}
+	})
+}
+
+// SnapshotNodeError reports a node snapshot failed to apply.
+type SnapshotNodeError struct {
+	Index int      // index of node in snapshot.
+	Names []string // resolved names.
+	Err   error
+}
+
+func (e *SnapshotNodeError) Error() string {
+	return fmt.Sprintf("failed to apply node snapshot. (err = \"%v\") {index = %v, names = %v}", e.Err, e.Index, e.Names)
+}
+
+// ApplySnapshotOption contains extra requirements for applying snapshot.
+type ApplySnapshotOption interface{}
+
+type replaceSnapshotOption struct{}
+
+// ReplaceSnapshot creates an option to make cluster identical to snapshot.
+// Values are overwritten without syncing, and entries and nodes absent in snapshot are removed, except myself.
+func ReplaceSnapshot() ApplySnapshotOption { return replaceSnapshotOption{} }
+
+// ApplySnapshot imports snapshot into cluster in a transaction.
+// Nodes in snapshot are matched to existing ones by resolved names, or created if not found.
+// By default, entries are merged by validators.
+// Nodes failing to apply are skipped and reported by *SnapshotNodeError in the returned Errors.
+func (c *Cluster) ApplySnapshot(s *proto.Cluster, opts ...ApplySnapshotOption) error {
+	if s == nil {
+		return nil
+	}
+
+	replace := false
+	for _, opt := range opts {
+		switch opt.(type) {
+		case replaceSnapshotOption:
+			replace = true
+		}
+	}
+
+	var errs Errors
+
+	if err := c.Txn(func(t *Transaction) bool {
+		applied := make(map[*Node]struct{}, len(s.Nodes))
+
+		for idx, ns := range s.Nodes {
+			if ns == nil {
+				continue
+			}
+			node, names, err := t.applyNodeSnapshot(ns, replace)
+			if node != nil {
+				applied[node] = struct{}{}
+			}
+			if err != nil {
+				errs = append(errs, &SnapshotNodeError{Index: idx, Names: names, Err: err})
+			}
+			if t.Prefail() != nil {
+				return false
+			}
+		}
+
+		if replace {
+			var removed []*Node
+			t.RangeNode(func(n *Node) bool {
+				if _, exists := applied[n]; !exists {
+					removed = append(removed, n)
+				}
+				return true
+			}, true, false)
+			for _, node := range removed {
+				if _, err := t.RemoveNode(node); err != nil {
+					errs = append(errs, err)
+					return false
+				}
+			}
+		}
+
+		return true
+	}, MembershipModification()); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs.AsError()
+}
+
+func (t *Transaction) applyNodeSnapshot(s *proto.Node, replace bool) (node *Node, names []string, err error) {
+	if node, names, err = t.MostPossibleNodeFromProtobuf(s.Kvs); err != nil {
+		return nil, nil, err
+	}
+	if len(names) < 1 {
+		return nil, nil, ErrUnresolvableNodeSnapshot
+	}
+
+	created := false
+	if node == nil {
+		if node, err = t.NewNode(); err != nil {
+			return nil, names, err
+		}
+		created = true
+	}
+
+	if replace {
+		err = t.replaceNodeEntries(node, s)
+	} else {
+		err = t.MergeNodeSnapshot(node, s, false, false, true)
+	}
+	if err != nil && created { // drop the new node.
+		if _, ierr := t.RemoveNode(node); ierr != nil {
+			t.Cluster.log.Warnf("cannot drop new node when snapshot fails to apply. (err = \"%v\")", ierr)
+		}
+		node = nil
+	}
+
+	return node, names, err
+}
+
+// replaceNodeEntries overwrites node entries with snapshot.
+func (t *Transaction) replaceNodeEntries(n *Node, s *proto.Node) (err error) {
+	// validate first to keep node untouched in case of invalid snapshot.
+	keys := make(map[string]struct{}, len(s.Kvs))
+	for _, kv := range s.Kvs {
+		validator := t.Cluster.getKeyModel(kv.Key).getValidator()
+		if validator == nil {
+			return fmt.Errorf("%v. {key = \"%v\"}", ErrValidatorMissing, kv.Key)
+		}
+		if !validator.Validate(KeyValue{Key: kv.Key, Value: kv.Value}) {
+			return fmt.Errorf("%v. {key = \"%v\"}", ErrInvalidKeyValue, kv.Key)
+		}
+		keys[kv.Key] = struct{}{}
+	}
+
+	var deleted []string
+	t.RangeNodeKeys(n, func(key string, pastExists bool) bool {
+		if _, exists := keys[key]; !exists {
+			deleted = append(deleted, key)
+		}
+		return true
+	})
+	for _, key := range deleted {
+		if err = t.dropEntry(n, key); err != nil {
+			return err
+		}
+	}
+
+	for _, kv := range s.Kvs {
+		lc := atomic.AddUint32(&t.lc, 1) // increase logic clock.
+
+		t.lock.Lock()
+		log, _, err := t.getLatestLog(n, kv.Key, true, lc)
+		if err == nil {
+			if err = log.txn.SetRawValue(kv.Value); err == nil {
+				log.deletion, log.merged = false, true
+			}
+		}
+		t.lock.Unlock()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
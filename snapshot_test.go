@@ -0,0 +1,283 @@
+package sladder
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crossmesh/sladder/proto"
+	"github.com/stretchr/testify/assert"
+	mock "github.com/stretchr/testify/mock"
+)
+
+type memorySnapshotStore struct {
+	lock  sync.Mutex
+	s     *proto.Snapshot
+	saves int
+}
+
+func (m *memorySnapshotStore) Load() (*proto.Snapshot, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.s, nil
+}
+
+func (m *memorySnapshotStore) Save(s *proto.Snapshot) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.s = s
+	m.saves++
+	return nil
+}
+
+func (m *memorySnapshotStore) Saves() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.saves
+}
+
+func newTestSnapshotCluster(t *testing.T, options ...ClusterOption) *Cluster {
+	e := &MockEngineInstance{}
+	e.Mock.On("Init", mock.Anything).Return(error(nil))
+	e.Mock.On("Close").Return(error(nil))
+	r := &MockNodeNameKVResolver{}
+	r.UseKeyAsID("id")
+
+	c, _, err := NewClusterWithNameResolver(e, r, options...)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return c
+}
+
+func TestSnapshotWarmRestart(t *testing.T) {
+	store := &memorySnapshotStore{}
+
+	c := newTestSnapshotCluster(t, WithSnapshotStore(store), WithSnapshotInterval(0))
+	assert.False(t, c.Stale())
+	assert.NoError(t, c.RegisterKey("id", &StringValidator{}, false, 0))
+	assert.NoError(t, c.RegisterKey("key1", &StringValidator{}, false, 0))
+	for _, name := range []string{"n1", "n2"} {
+		n, err := c.NewNode()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.NoError(t, c.Txn(func(tx *Transaction) bool {
+			for key, value := range map[string]string{"id": name, "key1": "v-" + name} {
+				rtx, err := tx.KV(n, key)
+				if !assert.NoError(t, err) {
+					return false
+				}
+				rtx.(*StringTxn).Set(value)
+			}
+			return true
+		}))
+	}
+	assert.NoError(t, c.Txn(func(tx *Transaction) bool {
+		rtx, err := tx.KV(c.Self(), "id")
+		if !assert.NoError(t, err) {
+			return false
+		}
+		rtx.(*StringTxn).Set("self")
+		return true
+	}))
+
+	assert.NoError(t, c.SaveSnapshot())
+	if !assert.NotNil(t, store.s) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{"id", "key1"}, store.s.Keys)
+	assert.Equal(t, 2, len(store.s.Nodes)) // myself excluded.
+	assert.NoError(t, c.Quit())
+	assert.Equal(t, 2, store.Saves())
+
+	t.Run("restore", func(t *testing.T) {
+		saves := store.Saves()
+		c := newTestSnapshotCluster(t, WithSnapshotStore(store), WithSnapshotInterval(0))
+		assert.True(t, c.Stale())
+
+		// wait for keys.
+		assert.NoError(t, c.RegisterKey("id", &StringValidator{}, false, 0))
+		assert.Nil(t, c.GetNode("n1"))
+		assert.NoError(t, c.RegisterKey("key1", &StringValidator{}, false, 0))
+		for _, name := range []string{"n1", "n2"} {
+			n := c.GetNode(name)
+			if assert.NotNil(t, n, "node %v not restored", name) {
+				assert.Contains(t, n.KeyValueEntries(true), &KeyValue{Key: "key1", Value: "v-" + name})
+			}
+		}
+		assert.Nil(t, c.GetNode("self"))
+
+		// provisional view is not saved.
+		assert.NoError(t, c.SaveSnapshot())
+		assert.Equal(t, saves, store.Saves())
+
+		c.Synced()
+		assert.False(t, c.Stale())
+		assert.NoError(t, c.SaveSnapshot())
+		assert.Equal(t, saves+1, store.Saves())
+	})
+
+	t.Run("synced_before_restore", func(t *testing.T) {
+		c := newTestSnapshotCluster(t, WithSnapshotStore(store), WithSnapshotInterval(0))
+		assert.True(t, c.Stale())
+		c.Synced()
+		assert.False(t, c.Stale())
+		assert.NoError(t, c.RegisterKey("id", &StringValidator{}, false, 0))
+		assert.NoError(t, c.RegisterKey("key1", &StringValidator{}, false, 0))
+		assert.Nil(t, c.GetNode("n1"))
+	})
+
+	t.Run("key_pattern", func(t *testing.T) {
+		store := &memorySnapshotStore{}
+		c := newTestSnapshotCluster(t, WithSnapshotStore(store), WithSnapshotInterval(0))
+		assert.NoError(t, c.RegisterKey("id", &StringValidator{}, false, 0))
+		assert.NoError(t, c.RegisterKeyPrefix("app.", &StringValidator{}, false, 0))
+		n, err := c.NewNode()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.NoError(t, c.Txn(func(tx *Transaction) bool {
+			for key, value := range map[string]string{"id": "n1", "app.port": "80"} {
+				rtx, err := tx.KV(n, key)
+				if !assert.NoError(t, err) {
+					return false
+				}
+				rtx.(*StringTxn).Set(value)
+			}
+			return true
+		}))
+		assert.NoError(t, c.SaveSnapshot())
+		assert.Equal(t, []string{"app.port", "id"}, store.s.Keys)
+
+		// wait for the prefix registered later.
+		c = newTestSnapshotCluster(t, WithSnapshotStore(store), WithSnapshotInterval(0))
+		assert.NoError(t, c.RegisterKey("id", &StringValidator{}, false, 0))
+		assert.Nil(t, c.GetNode("n1"))
+		assert.NoError(t, c.RegisterKeyPrefix("app.", &StringValidator{}, false, 0))
+		if n := c.GetNode("n1"); assert.NotNil(t, n) {
+			assert.Contains(t, n.KeyValueEntries(true), &KeyValue{Key: "app.port", Value: "80"})
+		}
+	})
+
+	t.Run("periodical", func(t *testing.T) {
+		store := &memorySnapshotStore{}
+		c := newTestSnapshotCluster(t, WithSnapshotStore(store), WithSnapshotInterval(time.Millisecond*10))
+		assert.Eventually(t, func() bool { return store.Saves() > 1 }, time.Second, time.Millisecond*10)
+		assert.NoError(t, c.Quit())
+	})
+}
+
+func TestFileSnapshotStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sladder-snapshot")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	s := FileSnapshotStore(filepath.Join(dir, "snapshot"))
+	snap, err := s.Load()
+	assert.NoError(t, err)
+	assert.Nil(t, snap)
+
+	assert.NoError(t, s.Save(&proto.Snapshot{Keys: []string{"id"}, Timestamp: 1}))
+	snap, err = s.Load()
+	assert.NoError(t, err)
+	if assert.NotNil(t, snap) {
+		assert.Equal(t, []string{"id"}, snap.Keys)
+		assert.Equal(t, int64(1), snap.Timestamp)
+	}
+
+	// corrupted snapshot is ignored.
+	assert.NoError(t, ioutil.WriteFile(string(s), []byte("corrupted"), 0600))
+	_, err = s.Load()
+	assert.Error(t, err)
+	c := newTestSnapshotCluster(t, WithSnapshotStore(s), WithSnapshotInterval(0))
+	assert.False(t, c.Stale())
+}
+
+type rejectBadValidator struct{ StringValidator }
+
+func (v rejectBadValidator) Sync(lr, rr *KeyValue) (bool, error) {
+	if rr != nil && rr.Value == "bad" {
+		return false, ErrInvalidKeyValue
+	}
+	return v.StringValidator.Sync(lr, rr)
+}
+
+func (v rejectBadValidator) Validate(kv KeyValue) bool { return kv.Value != "bad" }
+
+func TestApplySnapshot(t *testing.T) {
+	newNode := func(kvs ...string) *proto.Node {
+		n := &proto.Node{}
+		for i := 0; i+1 < len(kvs); i += 2 {
+			n.Kvs = append(n.Kvs, &proto.Node_KeyValue{Key: kvs[i], Value: kvs[i+1]})
+		}
+		return n
+	}
+	setup := func(t *testing.T) *Cluster {
+		c := newTestSnapshotCluster(t)
+		assert.NoError(t, c.RegisterKey("id", &StringValidator{}, false, 0))
+		assert.NoError(t, c.RegisterKey("key1", &StringValidator{}, false, 0))
+		assert.NoError(t, c.RegisterKey("key2", rejectBadValidator{}, false, 0))
+		assert.NoError(t, c.ApplySnapshot(&proto.Cluster{Nodes: []*proto.Node{
+			newNode("id", "n1", "key1", "a", "key2", "b"),
+			newNode("id", "n2", "key1", "a"),
+		}}))
+		return c
+	}
+	entries := func(t *testing.T, c *Cluster, name string) map[string]string {
+		n := c.GetNode(name)
+		if n == nil {
+			return nil
+		}
+		m := make(map[string]string)
+		for _, kv := range n.KeyValueEntries(true) {
+			m[kv.Key] = kv.Value
+		}
+		return m
+	}
+
+	t.Run("merge", func(t *testing.T) {
+		c := setup(t)
+		assert.Equal(t, map[string]string{"id": "n1", "key1": "a", "key2": "b"}, entries(t, c, "n1"))
+		assert.Equal(t, map[string]string{"id": "n2", "key1": "a"}, entries(t, c, "n2"))
+
+		err := c.ApplySnapshot(&proto.Cluster{Nodes: []*proto.Node{
+			newNode("id", "n1", "key1", "c"),
+			newNode("id", "n3", "key2", "bad"),
+			newNode("key1", "unnamed"),
+		}})
+		if assert.Error(t, err) {
+			errs, _ := err.(Errors)
+			if assert.Equal(t, 2, len(errs)) {
+				assert.Equal(t, 1, errs[0].(*SnapshotNodeError).Index)
+				assert.Equal(t, []string{"n3"}, errs[0].(*SnapshotNodeError).Names)
+				assert.Equal(t, ErrUnresolvableNodeSnapshot, errs[1].(*SnapshotNodeError).Err)
+			}
+		}
+		assert.Equal(t, map[string]string{"id": "n1", "key1": "c", "key2": "b"}, entries(t, c, "n1"))
+		assert.Equal(t, map[string]string{"id": "n2", "key1": "a"}, entries(t, c, "n2"))
+		assert.Nil(t, c.GetNode("n3"))
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		c := setup(t)
+		err := c.ApplySnapshot(&proto.Cluster{Nodes: []*proto.Node{
+			newNode("id", "n1", "key1", "c"),
+			newNode("id", "n3", "key2", "d"),
+			newNode("id", "n4", "key2", "bad"),
+		}}, ReplaceSnapshot())
+		if assert.Error(t, err) {
+			assert.Equal(t, 2, err.(*SnapshotNodeError).Index)
+		}
+		assert.Equal(t, map[string]string{"id": "n1", "key1": "c"}, entries(t, c, "n1"))
+		assert.Equal(t, map[string]string{"id": "n3", "key2": "d"}, entries(t, c, "n3"))
+		assert.Nil(t, c.GetNode("n2"))
+		assert.Nil(t, c.GetNode("n4"))
+		assert.True(t, c.ContainNodes(c.Self()))
+	})
+}
@@ -182,7 +182,7 @@ func (t *Transaction) mergeNodeEntries(n *Node, deletion, failMissingValidator,
 			err = fmt.Errorf("merge snapshot fails to apply raw value. (err = \"%v\") {key = \"%v\", node = \"%v\"}", err, diff, n.PrintableName())
 			break
 		}
-		diff.value = old
+		diff.value, diff.log.merged = old, true
 
 		lastLog++
 	}
@@ -2,6 +2,7 @@ package sladder
 
 import (
 	"errors"
+	"fmt"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -79,8 +80,10 @@ type txnLog struct {
 	txn      KVTransaction
 
 	validator KVValidator
+	flags     uint32
 	lc        uint32
 	new       bool
+	merged    bool // modified by merging snapshot.
 }
 
 type nodeOpLog struct {
@@ -221,6 +224,11 @@ func (c *Cluster) Txn(do func(*Transaction) bool, opts ...TxnOption) (err error)
 		return rollback()
 	}
 
+	if err = t.enforceKeyFlags(); err != nil {
+		t.errs = append(t.errs, err)
+		return rollback()
+	}
+
 	if err = t.doNodeNaming(); err != nil {
 		t.errs = append(t.errs, err)
 		return rollback()
@@ -272,6 +280,24 @@ func (c *Cluster) Txn(do func(*Transaction) bool, opts ...TxnOption) (err error)
 	return nil
 }
 
+// enforceKeyFlags ensures that operations follow flags of keys.
+func (t *Transaction) enforceKeyFlags() error {
+	for ref, log := range t.logs {
+		if log.flags&ReadOnlyRemoteEntry == 0 || log.merged || ref.node == t.Cluster.self {
+			continue
+		}
+		if log.txn.Updated() || (log.deletion && !log.new) {
+			return fmt.Errorf("%v. entry is read-only. {node = %v, key = %v}", ErrTransactionCommitViolation, ref.node.PrintableName(), ref.key)
+		}
+	}
+	return nil
+}
+
+// KeyFlags returns registration flags of key.
+func (t *Transaction) KeyFlags(key string) uint32 {
+	return t.Cluster.getKeyModel(key).getFlags()
+}
+
 // DO NOT USE THIS DIRECTLY. Use NewNode() instead.
 // This is only used for cluster initialization.
 func (t *Transaction) _joinNode(node *Node) error {
@@ -560,6 +586,7 @@ func (t *Transaction) commit() (finalOps []*transactionFinalOp) {
 					Key:   ref.key,
 					Value: newValue,
 				},
+				flags:     log.flags,
 				validator: log.validator,
 			}
 			ref.node.kvs[ref.key] = entry
@@ -618,6 +645,60 @@ func (t *Transaction) Delete(n *Node, key string) (err error) {
 	return nil
 }
 
+// dropEntry removes KV entry from node bypassing key flags, e.g. on key registration changes or snapshot replacement.
+func (t *Transaction) dropEntry(n *Node, key string) error {
+	if err := t.Delete(n, key); err != nil {
+		return err
+	}
+	t.lock.Lock()
+	t.logs[txnKeyRef{node: n, key: key}].merged = true
+	t.lock.Unlock()
+	return nil
+}
+
+// migratedTxn is transaction of entry value converted for new validator.
+type migratedTxn struct {
+	txn    KVTransaction
+	before string
+}
+
+func (t *migratedTxn) Updated() bool                  { return t.txn.After() != t.before }
+func (t *migratedTxn) After() string                  { return t.txn.After() }
+func (t *migratedTxn) Before() string                 { return t.before }
+func (t *migratedTxn) SetRawValue(value string) error { return t.txn.SetRawValue(value) }
+func (t *migratedTxn) KVTransaction() KVTransaction   { return t.txn }
+
+// migrateEntry replaces entry value with raw value in format of new validator.
+func (t *Transaction) migrateEntry(n *Node, key string, validator KVValidator, flags uint32, raw string) (err error) {
+	if err := t.Prefail(); err != nil { // reject in case of broken txn
+		return err
+	}
+
+	var (
+		log *txnLog
+		txn KVTransaction
+	)
+
+	lc := atomic.AddUint32(&t.lc, 1) // increase logic clock.
+
+	t.lock.Lock()
+	log, _, err = t.getLatestLog(n, key, true, lc)
+	defer t.lock.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if txn, err = validator.Txn(KeyValue{Key: key, Value: raw}); err != nil {
+		return err
+	}
+	log.txn = &migratedTxn{txn: txn, before: log.txn.Before()}
+	log.validator, log.flags = validator, flags
+	log.deletion, log.merged = false, true
+
+	return nil
+}
+
 func (t *Transaction) getLatestLog(n *Node, key string, create bool, lc uint32) (log *txnLog, created bool, err error) {
 	ref := txnKeyRef{node: n, key: key}
 
@@ -640,7 +721,8 @@ func (t *Transaction) getLatestLog(n *Node, key string, create bool, lc uint32)
 		txn  KVTransaction
 	)
 
-	validator, _ := t.Cluster.validators[key]
+	model := t.Cluster.getKeyModel(key)
+	validator := model.getValidator()
 
 	if kver, _ := t.Cluster.engine.(TxnKVCoordinator); kver != nil {
 		latestSnap, err := kver.TransactionBeginKV(t, n, key)
@@ -656,6 +738,7 @@ func (t *Transaction) getLatestLog(n *Node, key string, create bool, lc uint32)
 	log = &txnLog{
 		lc:        lc,
 		validator: validator,
+		flags:     model.getFlags(),
 		new:       false,
 	}
 
@@ -669,6 +752,7 @@ func (t *Transaction) getLatestLog(n *Node, key string, create bool, lc uint32)
 			// lock this entry.
 			e.lock.Lock()
 			validator, snap = e.validator, &e.KeyValue
+			log.flags = e.flags
 
 			defer func() {
 				if err != nil {
@@ -727,6 +811,40 @@ func (t *Transaction) KV(n *Node, key string) (txn KVTransaction, err error) {
 	return t.getKV(n, key, lc)
 }
 
+// ConflictError raises when current value differs from the expected one in compare-and-swap.
+type ConflictError struct {
+	Node     *Node
+	Key      string
+	Expected string
+	Current  string
+	Exists   bool
+}
+
+func (e *ConflictError) Error() string {
+	if !e.Exists {
+		return fmt.Sprintf("value conflicts. entry not exists. {node = %v, key = %v}", e.Node.PrintableName(), e.Key)
+	}
+	return fmt.Sprintf("value conflicts. (expected = \"%v\", current = \"%v\") {node = %v, key = %v}", e.Expected, e.Current, e.Node.PrintableName(), e.Key)
+}
+
+// CompareAndSwap sets real value of entry to new if the current one equals to old, otherwise *ConflictError is returned.
+// A missing entry is considered to have empty value.
+func (t *Transaction) CompareAndSwap(n *Node, key, old, new string) error {
+	exists := t.KeyExists(n, key)
+	rtx, err := t.KV(n, key)
+	if err != nil {
+		return err
+	}
+	current := ""
+	if exists {
+		current = rtx.After()
+	}
+	if current != old {
+		return &ConflictError{Node: n, Key: key, Expected: old, Current: current, Exists: exists}
+	}
+	return rtx.SetRawValue(new)
+}
+
 // KeyExists checks whether keys exists in node.
 func (t *Transaction) KeyExists(node *Node, keys ...string) bool {
 	if node == nil || len(keys) < 1 {